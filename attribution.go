@@ -0,0 +1,159 @@
+// attribution.go -- attributing failures to helpers.
+
+package testig
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// StackFrame is a single frame of the call stack recorded for a failure.
+// Function is the fully qualified function name, as in runtime.Frame, and
+// Helper reports whether the function had been marked with Helper when the
+// failure was recorded.
+type StackFrame struct {
+	Function string
+	File     string
+	Line     int
+	Helper   bool
+}
+
+// String stringifies the StackFrame as the function name, less its import
+// path, and the base name of the file with the line number.
+func (f StackFrame) String() string {
+	fn := f.Function
+	if i := strings.LastIndex(fn, "/"); i >= 0 {
+		fn = fn[i+1:]
+	}
+	return fmt.Sprintf("%s (%s:%d)", fn, filepath.Base(f.File), f.Line)
+}
+
+// Reported returns the frame of the Stack that testing.T would report as the
+// location of the Event, and true; or false if the Stack is empty, as for
+// Events other than failures.  The Stack already leaves out testig's own
+// frames, and each frame's Helper mark reflects the Helper calls made before
+// the Event was recorded, so the reported frame is the first one not marked,
+// or the last frame if all of them are.  This is the same frame that gives
+// the Event its File and Line.
+func (e Event) Reported() (StackFrame, bool) {
+	i := reportedFrame(e.Stack)
+	if i < 0 {
+		return StackFrame{}, false
+	}
+	return e.Stack[i], true
+}
+
+// Attribution describes the call stack of a failure Event, innermost frame
+// first, marking each helper and highlighting the reported frame:
+//
+//	Error at foo_test.go:30: not positive
+//	    helper  mylib.requirePositive (mylib.go:12)
+//	    helper  mylib.RequireAll (mylib.go:20)
+//	  >         foo_test.Test_Foo.func1 (foo_test.go:30)
+//	            foo_test.Test_Foo (foo_test.go:33)
+//
+// Only the first line of the message is shown.  For other Events it returns
+// an empty string.
+func (e Event) Attribution() string {
+
+	if !e.Failure() {
+		return ""
+	}
+	msg, _, _ := strings.Cut(e.Message, "\n")
+	header := fmt.Sprintf("%s at %s:%d: %s",
+		e.Kind, filepath.Base(e.File), e.Line, msg)
+	lines := []string{strings.TrimSuffix(header, " ")}
+	reported := reportedFrame(e.Stack)
+	for i, frame := range e.Stack {
+		mark, label := " ", ""
+		if i == reported {
+			mark = ">"
+		}
+		if frame.Helper {
+			label = "helper"
+		}
+		lines = append(lines, fmt.Sprintf("  %s %-6s  %s", mark, label, frame))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Attribution describes the call stack of every failure recorded by tt and
+// its subtests, in the order of the tests and then of the failures, each as
+// for Event.Attribution and headed by the name of its test.  If there are no
+// failures it returns an empty string.
+func (tt *TestTester) Attribution() string {
+	return strings.Join(tt.attributions(), "\n")
+}
+
+// attributions returns the lines of the Attribution.
+func (tt *TestTester) attributions() []string {
+
+	lines := []string{}
+	tt.mu.RLock()
+	events := append([]Event{}, tt.Events...)
+	tt.mu.RUnlock()
+	for _, ev := range events {
+		if ev.Failure() {
+			lines = append(lines, "--- "+tt.name)
+			lines = append(lines, strings.Split(ev.Attribution(), "\n")...)
+		}
+	}
+	for _, sub := range tt.Subtests() {
+		lines = append(lines, sub.attributions()...)
+	}
+	return lines
+}
+
+// AssertHelpersMarked fails with msgAndArgs unless tt or its subtests
+// recorded at least one failure, and each failure is reported at a location
+// outside the functions whose fully qualified names match the regular
+// expression fn, which must compile.  Since testing.T reports the first
+// function not marked with Helper, this checks that the matching functions
+// mark themselves as helpers wherever they fail.  It is safe to omit
+// msgAndArgs.  It returns true if all the failures were attributed as
+// expected:
+//
+//	tt := NewTestTester()
+//	tt.RunHelper(func(t TT) { mylib.RequireAll(t, -1) })
+//	AssertHelpersMarked(t, tt, `^example\.com/mylib\.`)
+//
+// The failure message includes the Attribution of every failure.
+func AssertHelpersMarked(t TT, tt *TestTester, fn string, msgAndArgs ...interface{}) bool {
+
+	re := regexp.MustCompile(fn)
+	found := false
+	ok := true
+	var check func(tt *TestTester)
+	check = func(tt *TestTester) {
+		tt.mu.RLock()
+		events := append([]Event{}, tt.Events...)
+		tt.mu.RUnlock()
+		for _, ev := range events {
+			if !ev.Failure() {
+				continue
+			}
+			found = true
+			if frame, _ := ev.Reported(); re.MatchString(frame.Function) {
+				ok = false
+			}
+		}
+		for _, sub := range tt.Subtests() {
+			check(sub)
+		}
+	}
+	check(tt)
+
+	if !found {
+		return assert.Fail(t, "No failures to attribute", msgAndArgs...)
+	}
+	if !ok {
+		return assert.Fail(t, fmt.Sprintf(
+			"Failure reported in function matching /%s/:\n%s",
+			fn, tt.Attribution()), msgAndArgs...)
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+// attribution_test.go
+
+package testig_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+func markedFail(t testig.TT, msg string) {
+	t.(testig.TT2).Helper()
+	t.Error(msg)
+}
+
+func markedOuter(t testig.TT) {
+	t.(testig.TT2).Helper()
+	markedFail(t, "from marked")
+}
+
+func unmarkedOuter(t testig.TT) {
+	markedFail(t, "from unmarked")
+}
+
+func Test_Event_Stack(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {
+		t.Log("not a failure")
+		markedOuter(t)
+	})
+
+	if !assert.Equal(2, len(tt.Events), "two Events") {
+		return
+	}
+	assert.Nil(tt.Events[0].Stack, "no Stack for Log")
+	_, ok := tt.Events[0].Reported()
+	assert.False(ok, "nothing Reported for Log")
+	assert.Equal("", tt.Events[0].Attribution(), "no Attribution for Log")
+
+	ev := tt.Events[1]
+	if !assert.Equal(3, len(ev.Stack), "stack up to the runner") {
+		return
+	}
+	assert.Regexp(`\.markedFail$`, ev.Stack[0].Function, "innermost")
+	assert.True(ev.Stack[0].Helper, "innermost is helper")
+	assert.Regexp(`\.markedOuter$`, ev.Stack[1].Function, "outer")
+	assert.True(ev.Stack[1].Helper, "outer is helper")
+	assert.Regexp(`\.Test_Event_Stack\.func1$`, ev.Stack[2].Function,
+		"test function")
+	assert.False(ev.Stack[2].Helper, "test function not helper")
+
+	frame, ok := ev.Reported()
+	assert.True(ok, "Reported")
+	assert.Equal(ev.Stack[2], frame, "test function Reported")
+	assert.Equal(frame.File, ev.File, "File as Reported")
+	assert.Equal(frame.Line, ev.Line, "Line as Reported")
+
+}
+
+// fileLineParenRegexp matches the locations in Attribution frames.
+var fileLineParenRegexp = regexp.MustCompile(`\(\S+\.go:\d+\)`)
+
+func Test_Event_Attribution(t *testing.T) {
+
+	tt := testig.NewNamedTestTester("TestAttr")
+	tt.RunHelper(func(t testig.TT) {
+		testig.RunSubtest(t, "sub", func(t testig.TT) { unmarkedOuter(t) })
+		t.Fail()
+	})
+
+	exp := `--- TestAttr
+Fail at x_test.go:1:
+  >         testig_test.Test_Event_Attribution.func1 (x_test.go:1)
+--- TestAttr/sub
+Error at x_test.go:1: from unmarked
+    helper  testig_test.markedFail (x_test.go:1)
+  >         testig_test.unmarkedOuter (x_test.go:1)
+            testig_test.Test_Event_Attribution.func1.1 (x_test.go:1)`
+	act := fileLineParenRegexp.ReplaceAllString(
+		anyFileLine(tt.Attribution()), "(x_test.go:1)")
+	assert.Equal(t, exp, act, "subtests after parent")
+
+}
+
+func Test_AssertHelpersMarked(t *testing.T) {
+
+	assert := assert.New(t)
+
+	const fn = `\.(markedFail|markedOuter|unmarkedOuter)$`
+
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) { markedOuter(t) })
+	assert.True(testig.AssertHelpersMarked(t, tt, fn), "marked passes")
+
+	tt = testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) { unmarkedOuter(t) })
+	tester := testig.NewTestTester()
+	ok := testig.AssertHelpersMarked(tester, tt, fn, "my %s", "test")
+	assert.False(ok, "unmarked fails")
+	if assert.Equal(1, len(tester.Logs), "one thing logged") {
+		msg := testig.StripTestify(tester.Logs[0])
+		assert.Contains(msg, "Failure reported in function matching /"+fn+"/:")
+		assert.Contains(msg, "  >         testig_test.unmarkedOuter")
+		assert.True(strings.HasSuffix(msg, "my test"), "message last")
+	}
+
+	tt = testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) { t.Log("fine") })
+	tester = testig.NewTestTester()
+	assert.False(testig.AssertHelpersMarked(tester, tt, fn), "no failures")
+	assert.Contains(tester.Logs[0], "No failures to attribute")
+
+}
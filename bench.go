@@ -0,0 +1,304 @@
+// bench.go -- testing benchmark helpers.
+
+package testig
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// BB defines an interface implemented by both the BenchTester and testing.B,
+// for benchmark helper functions:
+//
+//	func BenchHelper(b BB, f func()) {
+//	    b.ReportAllocs()
+//	    b.ResetTimer()
+//	    for i := 0; i < BenchN(b); i++ {
+//	        f()
+//	    }
+//	}
+//
+// As N is a field and not a method it can not be part of the interface; use
+// BenchN (or Loop) instead.  Likewise use RunParallel to run parallel
+// benchmarks.
+type BB interface {
+	TT2
+	Elapsed() time.Duration
+	Loop() bool
+	ReportAllocs()
+	ReportMetric(n float64, unit string)
+	ResetTimer()
+	SetBytes(n int64)
+	SetParallelism(p int)
+	StartTimer()
+	StopTimer()
+}
+
+// PB defines the interface of testing.PB, which is implemented by both the
+// BenchTester's parallel runner and testing.PB.
+type PB interface {
+	Next() bool
+}
+
+// BenchN returns the N property of b, which must be a *testing.B or a
+// *BenchTester; for anything else it panics.
+func BenchN(b BB) int {
+	switch b := b.(type) {
+	case *testing.B:
+		return b.N
+	case *BenchTester:
+		return b.N
+	}
+	panic(fmt.Sprintf("BenchN: can not get N from %T", b))
+}
+
+// RunParallel runs body in parallel on b, which must be a *testing.B or a
+// *BenchTester; for anything else it panics.  It is the BB-friendly way to
+// call b.RunParallel.
+func RunParallel(b BB, body func(PB)) {
+	switch b := b.(type) {
+	case *testing.B:
+		b.RunParallel(func(pb *testing.PB) { body(pb) })
+		return
+	case *BenchTester:
+		b.RunParallel(body)
+		return
+	}
+	panic(fmt.Sprintf("RunParallel: can not run in parallel on %T", b))
+}
+
+// Timer calls as recorded in a BenchTester's TimerCalls.
+const (
+	TimerReset = "ResetTimer"
+	TimerStart = "StartTimer"
+	TimerStop  = "StopTimer"
+)
+
+// BenchTester implements the BB interface in a way that helps us test our
+// benchmark helper functions.  It embeds a TestTester, which records the
+// usual TT calls.
+//
+// Run a benchmark helper with RunBenchmark, setting N first to the number of
+// iterations the helper should run; then inspect the calls it made.  As with
+// the TestTester, the exported properties should only be read when no
+// benchmark function is running.
+type BenchTester struct {
+	*TestTester
+	N              int
+	TimerCalls     []string           // TimerReset, TimerStart, TimerStop
+	Metrics        map[string]float64 // by unit, from ReportMetric
+	Bytes          int64              // from SetBytes
+	AllocsReported bool               // by ReportAllocs
+	Parallelism    int                // from SetParallelism
+	ParallelRuns   int                // calls to RunParallel
+	timerOn        bool
+	timerStart     time.Time
+	elapsed        time.Duration
+	loopN          int
+}
+
+// DefaultBenchTesterName is the name given to BenchTesters created by
+// NewBenchTester.
+const DefaultBenchTesterName = "BenchTester"
+
+// NewBenchTester returns a new BenchTester with N set to n.
+func NewBenchTester(n int) *BenchTester {
+	return &BenchTester{
+		TestTester:  NewNamedTestTester(DefaultBenchTesterName),
+		N:           n,
+		TimerCalls:  []string{},
+		Metrics:     map[string]float64{},
+		Parallelism: 1,
+	}
+}
+
+// RunBenchmark runs the benchmark function f with the BenchTester as its
+// argument, in the same way as RunHelper: in its own goroutine, stopping for
+// real on FailNow or SkipNow.  As with testing.B the timer is started before
+// f is called, and stopped after it finishes.
+func (bt *BenchTester) RunBenchmark(f func(BB)) RunStatus {
+
+	bt.mu.Lock()
+	bt.timerOn = false
+	bt.elapsed = 0
+	bt.loopN = 0
+	bt.mu.Unlock()
+
+	return bt.run(func() {
+		bt.startTimer()
+		defer bt.stopTimer()
+		f(bt)
+	})
+}
+
+// Elapsed mirrors the same-named function in testing.B: it returns the
+// measured elapsed time of the benchmark, counting only the time during
+// which the timer was running.
+func (bt *BenchTester) Elapsed() time.Duration {
+	bt.mu.RLock()
+	defer bt.mu.RUnlock()
+	if bt.timerOn {
+		return bt.elapsed + time.Since(bt.timerStart)
+	}
+	return bt.elapsed
+}
+
+// Loop mirrors the same-named function in testing.B, except that it always
+// runs exactly N iterations: it returns true N times, then false.  On the
+// first call it resets the timer, and when it returns false it stops it.
+func (bt *BenchTester) Loop() bool {
+
+	bt.mu.Lock()
+	first := bt.loopN == 0
+	more := bt.loopN < bt.N
+	bt.loopN++
+	bt.mu.Unlock()
+
+	if first {
+		bt.resetTimer()
+	}
+	if !more {
+		bt.stopTimer()
+	}
+	return more
+}
+
+// ReportAllocs mirrors the same-named function in testing.B, setting the
+// AllocsReported property.
+func (bt *BenchTester) ReportAllocs() {
+	bt.mu.Lock()
+	bt.AllocsReported = true
+	bt.mu.Unlock()
+}
+
+// ReportMetric mirrors the same-named function in testing.B, recording n in
+// the Metrics property under unit.  Like testing.B it panics if unit is empty
+// or contains whitespace.
+func (bt *BenchTester) ReportMetric(n float64, unit string) {
+	if unit == "" {
+		panic("metric unit must not be empty")
+	}
+	if strings.IndexFunc(unit, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	}) != -1 {
+		panic("metric unit must not contain whitespace")
+	}
+	bt.mu.Lock()
+	bt.Metrics[unit] = n
+	bt.mu.Unlock()
+}
+
+// ResetTimer mirrors the same-named function in testing.B: it zeroes the
+// elapsed time, without changing whether the timer is running.
+func (bt *BenchTester) ResetTimer() {
+	bt.timerCall(TimerReset)
+	bt.resetTimer()
+}
+
+// SetBytes mirrors the same-named function in testing.B, setting the Bytes
+// property.
+func (bt *BenchTester) SetBytes(n int64) {
+	bt.mu.Lock()
+	bt.Bytes = n
+	bt.mu.Unlock()
+}
+
+// SetParallelism mirrors the same-named function in testing.B, setting the
+// Parallelism property if p is at least 1.
+func (bt *BenchTester) SetParallelism(p int) {
+	if p < 1 {
+		return
+	}
+	bt.mu.Lock()
+	bt.Parallelism = p
+	bt.mu.Unlock()
+}
+
+// StartTimer mirrors the same-named function in testing.B: it starts timing
+// the benchmark.
+func (bt *BenchTester) StartTimer() {
+	bt.timerCall(TimerStart)
+	bt.startTimer()
+}
+
+// StopTimer mirrors the same-named function in testing.B: it stops timing the
+// benchmark.
+func (bt *BenchTester) StopTimer() {
+	bt.timerCall(TimerStop)
+	bt.stopTimer()
+}
+
+// RunParallel mirrors the same-named function in testing.B, except that body
+// takes a PB interface: it runs body in Parallelism times GOMAXPROCS
+// goroutines, whose PBs between them return true from Next exactly N times.
+// It increments the ParallelRuns property.
+func (bt *BenchTester) RunParallel(body func(PB)) {
+
+	bt.mu.Lock()
+	bt.ParallelRuns++
+	procs := bt.Parallelism * runtime.GOMAXPROCS(0)
+	remaining := int64(bt.N)
+	bt.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < procs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body(&benchPB{remaining: &remaining})
+		}()
+	}
+	wg.Wait()
+}
+
+// benchPB is the PB used by the BenchTester's RunParallel.
+type benchPB struct {
+	remaining *int64
+}
+
+// Next reports whether there are more iterations to execute.
+func (pb *benchPB) Next() bool {
+	return atomic.AddInt64(pb.remaining, -1) >= 0
+}
+
+// timerCall records a call to one of the timer methods.
+func (bt *BenchTester) timerCall(call string) {
+	bt.mu.Lock()
+	bt.TimerCalls = append(bt.TimerCalls, call)
+	bt.mu.Unlock()
+}
+
+// startTimer starts the timer without recording a call.
+func (bt *BenchTester) startTimer() {
+	bt.mu.Lock()
+	if !bt.timerOn {
+		bt.timerOn = true
+		bt.timerStart = time.Now()
+	}
+	bt.mu.Unlock()
+}
+
+// stopTimer stops the timer without recording a call.
+func (bt *BenchTester) stopTimer() {
+	bt.mu.Lock()
+	if bt.timerOn {
+		bt.timerOn = false
+		bt.elapsed += time.Since(bt.timerStart)
+	}
+	bt.mu.Unlock()
+}
+
+// resetTimer resets the timer without recording a call.
+func (bt *BenchTester) resetTimer() {
+	bt.mu.Lock()
+	bt.elapsed = 0
+	if bt.timerOn {
+		bt.timerStart = time.Now()
+	}
+	bt.mu.Unlock()
+}
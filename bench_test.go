@@ -0,0 +1,168 @@
+// bench_test.go
+
+package testig_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+// Both implement BB.
+var _ testig.BB = (*testing.B)(nil)
+var _ testig.BB = (*testig.BenchTester)(nil)
+
+func Test_NewBenchTester(t *testing.T) {
+
+	assert := assert.New(t)
+
+	bt := testig.NewBenchTester(10)
+	assert.Equal(10, bt.N, "N set")
+	assert.Equal(testig.DefaultBenchTesterName, bt.Name(), "Name set")
+	assert.Equal([]string{}, bt.TimerCalls, "no TimerCalls")
+	assert.Equal(map[string]float64{}, bt.Metrics, "no Metrics")
+	assert.Equal(1, bt.Parallelism, "Parallelism 1")
+
+}
+
+func Test_BenchTester_RunBenchmark(t *testing.T) {
+
+	assert := assert.New(t)
+
+	count := 0
+	helper := func(b testig.BB) {
+		b.ReportAllocs()
+		b.SetBytes(1024)
+		b.StopTimer()
+		time.Sleep(10 * time.Millisecond) // setup, not timed
+		b.ResetTimer()
+		b.StartTimer()
+		for i := 0; i < testig.BenchN(b); i++ {
+			count++
+		}
+		b.StopTimer()
+		b.ReportMetric(1.5, "widgets/op")
+	}
+
+	bt := testig.NewBenchTester(100)
+	status := bt.RunBenchmark(helper)
+	assert.Equal(testig.RunReturned, status, "returned")
+	assert.Equal(100, count, "ran N times")
+	assert.Equal([]string{
+		testig.TimerStop,
+		testig.TimerReset,
+		testig.TimerStart,
+		testig.TimerStop,
+	}, bt.TimerCalls, "timer calls recorded")
+	assert.True(bt.AllocsReported, "AllocsReported")
+	assert.Equal(int64(1024), bt.Bytes, "Bytes set")
+	assert.Equal(map[string]float64{"widgets/op": 1.5}, bt.Metrics,
+		"metric reported")
+	assert.True(bt.Elapsed() < 10*time.Millisecond,
+		"setup time excluded from Elapsed")
+	assert.False(bt.Failed(), "not Failed")
+
+}
+
+func Test_BenchTester_RunBenchmark_Fatal(t *testing.T) {
+
+	assert := assert.New(t)
+
+	bt := testig.NewBenchTester(1)
+	status := bt.RunBenchmark(func(b testig.BB) {
+		b.Fatal("no good")
+		b.ReportAllocs()
+	})
+	assert.Equal(testig.RunStopped, status, "stopped")
+	assert.False(bt.AllocsReported, "stopped for real")
+	assert.Equal([]string{"no good"}, bt.Logs, "logged")
+	assert.True(bt.Failed(), "Failed")
+
+}
+
+func Test_BenchTester_Loop(t *testing.T) {
+
+	assert := assert.New(t)
+
+	count := 0
+	bt := testig.NewBenchTester(5)
+	bt.RunBenchmark(func(b testig.BB) {
+		time.Sleep(10 * time.Millisecond) // setup, not timed
+		for b.Loop() {
+			count++
+		}
+	})
+	assert.Equal(5, count, "looped N times")
+	assert.True(bt.Elapsed() < 10*time.Millisecond,
+		"setup time excluded from Elapsed")
+
+}
+
+func Test_BenchTester_ReportMetric_Panics(t *testing.T) {
+
+	bt := testig.NewBenchTester(1)
+	testig.AssertPanicsWith(t, func() { bt.ReportMetric(1, "") },
+		"metric unit must not be empty")
+	testig.AssertPanicsWith(t, func() { bt.ReportMetric(1, "per op") },
+		"metric unit must not contain whitespace")
+
+}
+
+func Test_BenchTester_RunParallel(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var count int64
+	bt := testig.NewBenchTester(1000)
+	bt.RunBenchmark(func(b testig.BB) {
+		b.SetParallelism(0) // ignored
+		b.SetParallelism(4)
+		testig.RunParallel(b, func(pb testig.PB) {
+			for pb.Next() {
+				atomic.AddInt64(&count, 1)
+			}
+		})
+	})
+	assert.Equal(int64(1000), count, "ran N times in total")
+	assert.Equal(4, bt.Parallelism, "Parallelism set")
+	assert.Equal(1, bt.ParallelRuns, "ParallelRuns counted")
+
+}
+
+func Test_BenchN_Panics(t *testing.T) {
+
+	testig.AssertPanicsWith(t, func() { testig.BenchN(nil) },
+		"BenchN: can not get N from <nil>")
+
+}
+
+func Test_RunParallel_Panics(t *testing.T) {
+
+	testig.AssertPanicsWith(t,
+		func() { testig.RunParallel(nil, func(testig.PB) {}) },
+		"RunParallel: can not run in parallel on <nil>")
+
+}
+
+func Benchmark_BB_TestingB(b *testing.B) {
+
+	// Make sure the real thing works too.
+	var count int64
+	helper := func(b testig.BB) {
+		b.ReportAllocs()
+		testig.RunParallel(b, func(pb testig.PB) {
+			for pb.Next() {
+				atomic.AddInt64(&count, 1)
+			}
+		})
+		if count != int64(testig.BenchN(b)) {
+			b.Errorf("count %d != N %d", count, testig.BenchN(b))
+		}
+	}
+	helper(b)
+
+}
@@ -0,0 +1,141 @@
+// capture.go -- capturing the real standard output and error.
+
+package testig
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// capture is the state of an OutputRecorder while capturing.
+type capture struct {
+	stdout     *os.File     // original, restored by Stop
+	stderr     *os.File     // original, restored by Stop
+	writers    []*os.File   // write ends of the pipes
+	restoreFDs func() error // nil unless CaptureFDs
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+	err        error // first copying error
+}
+
+// Capture starts capturing the real standard output and error: os.Stdout and
+// os.Stderr are replaced by pipes, which feed Stdout and Stderr respectively
+// until Stop is called.  Thus output written with fmt.Println and the like
+// is recorded as if it had been written to the recorder.  Stop should
+// always be deferred, so that the originals are restored even if the code
+// under test panics:
+//
+//	if err := r.Capture(); err != nil {
+//	    t.Fatal(err)
+//	}
+//	defer r.Stop()
+//	DoSomethingNoisy()
+//	r.Stop()
+//	assert.Equal(t, "expected output\n", r.StdoutString())
+//
+// Code holding its own reference to the original os.Stdout or os.Stderr, as
+// the testing package does, is not captured unless CaptureFDs is set.  In
+// that case the underlying file descriptors 1 and 2 are also redirected to
+// the pipes, so that everything written to them is captured, including
+// output from cgo and from child processes inheriting them, and the output
+// of go test itself.  CaptureFDs is only supported on Linux; elsewhere
+// Capture returns an error if it is set.
+//
+// Since the standard output and error are global, capturing tests must not
+// be run in parallel with other tests writing to them.  Output is copied to
+// Stdout and Stderr as it is read from the pipes, but it is only certain to
+// have been copied once Stop has returned.
+func (r *OutputRecorder) Capture() error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.capture != nil {
+		return errors.New("testig: already capturing")
+	}
+
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		outR.Close()
+		outW.Close()
+		return err
+	}
+	c := &capture{
+		stdout:  os.Stdout,
+		stderr:  os.Stderr,
+		writers: []*os.File{outW, errW},
+	}
+	if r.CaptureFDs {
+		c.restoreFDs, err = redirectFDs(outW, errW)
+		if err != nil {
+			for _, f := range []*os.File{outR, outW, errR, errW} {
+				f.Close()
+			}
+			return err
+		}
+	}
+
+	os.Stdout = outW
+	os.Stderr = errW
+	c.wg.Add(2)
+	go c.copy(r.Stdout, outR)
+	go c.copy(r.Stderr, errR)
+	r.capture = c
+	return nil
+}
+
+// copy copies from the read end of a pipe to w until the pipe is closed.
+func (c *capture) copy(w io.Writer, pipe *os.File) {
+
+	defer c.wg.Done()
+	_, err := io.Copy(w, pipe)
+	pipe.Close()
+	if err != nil {
+		c.mu.Lock()
+		if c.err == nil {
+			c.err = err
+		}
+		c.mu.Unlock()
+	}
+}
+
+// Stop stops capturing started by Capture: the original os.Stdout and
+// os.Stderr, and with CaptureFDs the original file descriptors, are
+// restored, and all captured output is copied to Stdout and Stderr before
+// Stop returns.  Any child processes that inherited the captured file
+// descriptors must have exited, or Stop waits for them.
+//
+// It is safe to call Stop more than once, and when not capturing at all.
+// It returns the first error encountered in capturing or restoring, if any.
+func (r *OutputRecorder) Stop() error {
+
+	r.mu.Lock()
+	c := r.capture
+	r.capture = nil
+	r.mu.Unlock()
+	if c == nil {
+		return nil
+	}
+
+	os.Stdout = c.stdout
+	os.Stderr = c.stderr
+	var err error
+	if c.restoreFDs != nil {
+		err = c.restoreFDs()
+	}
+	for _, w := range c.writers {
+		if e := w.Close(); err == nil {
+			err = e
+		}
+	}
+	c.wg.Wait()
+	if err == nil {
+		err = c.err
+	}
+	return err
+}
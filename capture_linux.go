@@ -0,0 +1,40 @@
+// capture_linux.go -- redirecting file descriptors on Linux.
+
+package testig
+
+import (
+	"os"
+	"syscall"
+)
+
+// redirectFDs points file descriptors 1 and 2 at stdout and stderr, and
+// returns a function restoring the originals.
+func redirectFDs(stdout, stderr *os.File) (func() error, error) {
+
+	saved := []int{}
+	restore := func() error {
+		var err error
+		for fd, orig := range saved {
+			if e := syscall.Dup3(orig, fd+1, 0); err == nil {
+				err = e
+			}
+			syscall.Close(orig)
+		}
+		return err
+	}
+
+	for fd, f := range []*os.File{stdout, stderr} {
+		orig, err := syscall.Dup(fd + 1)
+		if err != nil {
+			restore()
+			return nil, err
+		}
+		syscall.CloseOnExec(orig)
+		saved = append(saved, orig)
+		if err := syscall.Dup3(int(f.Fd()), fd+1, 0); err != nil {
+			restore()
+			return nil, err
+		}
+	}
+	return restore, nil
+}
@@ -0,0 +1,46 @@
+// capture_linux_test.go
+
+package testig_test
+
+import (
+	"os/exec"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+func Test_OutputRecorder_CaptureFDs(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	r.CaptureFDs = true
+	if !assert.NoError(r.Capture(), "Capture") {
+		return
+	}
+	defer r.Stop()
+
+	syscall.Write(1, []byte("fd 1\n"))
+	syscall.Write(2, []byte("fd 2\n"))
+	sh, err := exec.LookPath("sh")
+	if err == nil {
+		// Inheriting the file descriptors themselves, not os.Stdout etc.
+		var pid int
+		pid, err = syscall.ForkExec(sh,
+			[]string{"sh", "-c", "echo child; echo child err >&2"},
+			&syscall.ProcAttr{Files: []uintptr{0, 1, 2}})
+		if err == nil {
+			var ws syscall.WaitStatus
+			_, err = syscall.Wait4(pid, &ws, 0, nil)
+		}
+	}
+
+	assert.NoError(r.Stop(), "Stop")
+	assert.NoError(err, "child ran")
+	assert.Equal("fd 1\nchild\n", r.StdoutString(), "stdout captured")
+	assert.Equal("fd 2\nchild err\n", r.StderrString(), "stderr captured")
+
+}
@@ -0,0 +1,15 @@
+// capture_other.go -- redirecting file descriptors elsewhere.
+
+//go:build !linux
+
+package testig
+
+import (
+	"errors"
+	"os"
+)
+
+// redirectFDs is not supported outside Linux.
+func redirectFDs(stdout, stderr *os.File) (func() error, error) {
+	return nil, errors.New("testig: CaptureFDs is only supported on Linux")
+}
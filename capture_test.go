@@ -0,0 +1,54 @@
+// capture_test.go
+
+package testig_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+func Test_OutputRecorder_Capture(t *testing.T) {
+
+	assert := assert.New(t)
+
+	stdout, stderr := os.Stdout, os.Stderr
+	r := testig.NewOutputRecorder()
+	if !assert.NoError(r.Capture(), "Capture") {
+		return
+	}
+	defer r.Stop()
+	assert.Error(r.Capture(), "already capturing")
+
+	fmt.Println("to stdout")
+	fmt.Fprint(os.Stderr, "to stderr")
+
+	assert.NoError(r.Stop(), "Stop")
+	assert.NoError(r.Stop(), "Stop again")
+	assert.Equal(stdout, os.Stdout, "os.Stdout restored")
+	assert.Equal(stderr, os.Stderr, "os.Stderr restored")
+	assert.Equal("to stdout\n", r.StdoutString(), "stdout captured")
+	assert.Equal("to stderr", r.StderrString(), "stderr captured")
+
+}
+
+func Test_OutputRecorder_Capture_Panic(t *testing.T) {
+
+	stdout := os.Stdout
+	r := testig.NewOutputRecorder()
+	testig.AssertPanicsWith(t, func() {
+		if err := r.Capture(); err != nil {
+			t.Fatal(err)
+		}
+		defer r.Stop()
+		fmt.Print("before panic")
+		panic("oops")
+	}, "oops")
+	assert.Equal(t, stdout, os.Stdout, "os.Stdout restored")
+	assert.Equal(t, "before panic", r.StdoutString(), "stdout captured")
+
+}
@@ -0,0 +1,137 @@
+// cases.go -- table-driven helper tests.
+
+package testig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// LogMatch says how the WantLogs of a HelperCase are compared to the actual
+// Logs.  In every case there must be as many actual as expected: WantLogs
+// with a LogMatch is shorthand for a MatchLogs of Lines with the
+// corresponding LineMatchers, i.e. Exactly, Regexp or Contains.
+type LogMatch int
+
+const (
+	// MatchExact requires each log message to equal the expected one.
+	MatchExact LogMatch = iota
+	// MatchRegexp requires each log message to match the expected one as a
+	// regular expression, which must compile.
+	MatchRegexp
+	// MatchContains requires each log message to contain the expected one.
+	MatchContains
+)
+
+// String stringifies the LogMatch.
+func (m LogMatch) String() string {
+	switch m {
+	case MatchExact:
+		return "exact"
+	case MatchRegexp:
+		return "regexp"
+	case MatchContains:
+		return "contains"
+	default:
+		return fmt.Sprintf("LogMatch(%d)", int(m))
+	}
+}
+
+// HelperCase is a single case for HelperCases: the helper is run with In, and
+// expected to end up as described by the rest.
+type HelperCase[In any] struct {
+	Name      string
+	In        In
+	WantFail  bool
+	WantSkip  bool
+	WantStop  bool
+	WantPanic bool
+	WantLogs  []string // matched with Lines according to LogMatch
+	LogMatch  LogMatch
+	MatchLogs LogMatcher // if set, used instead of WantLogs and LogMatch
+}
+
+// HelperCases runs helper with the In of each of cases, each in a subtest of
+// t named for the case and with a new TestTester run with RunHelper, and
+// checks that its Outcome is as expected.  All mismatches for a case are
+// reported in a single failure of its subtest, along with the helper's output
+// as go test -v would have printed it:
+//
+//	HelperCases(t, RequirePositive, []HelperCase[int]{
+//	    {Name: "ok", In: 1},
+//	    {Name: "zero", In: 0, WantFail: true, WantStop: true,
+//	        WantLogs: []string{"not positive"}, LogMatch: MatchContains},
+//	    {Name: "many", In: -2, WantFail: true, WantStop: true,
+//	        MatchLogs: InOrder(Contains("not positive"), Contains("-2"))},
+//	})
+//
+// The logs of a case are checked by its MatchLogs if set, and otherwise by
+// its WantLogs according to its LogMatch; either way a mismatch is described
+// by the LogMatcher's DiffLogs.
+//
+// Subtests are run with RunSubtest, so t must support them.  HelperCases
+// returns true if every case was as expected.
+func HelperCases[In any](t TT, helper func(TT, In), cases []HelperCase[In]) bool {
+
+	ok := true
+	for _, c := range cases {
+		if !RunSubtest(t, c.Name, func(t TT) { c.check(t, helper) }) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// check runs helper for the case and fails t unless it ended as expected.
+func (c HelperCase[In]) check(t TT, helper func(TT, In)) {
+
+	tt := NewNamedTestTester(nameOf(t))
+	status := tt.RunHelper(func(t TT) { helper(t, c.In) })
+
+	act := OutcomeOf(tt)
+	exp := Outcome{
+		Failed:   c.WantFail,
+		Skipped:  c.WantSkip,
+		Stopped:  c.WantStop,
+		Panicked: c.WantPanic,
+		Logs:     act.Logs,
+	}
+	diffs := []string{}
+	if diff := c.logMatcher().DiffLogs(act.Logs); diff != "" {
+		diffs = append(diffs, "Logs:     not matched")
+		for _, line := range strings.Split(diff, "\n") {
+			diffs = append(diffs, "  "+line)
+		}
+	}
+	if diff := exp.Diff(act); diff != "" {
+		diffs = append([]string{diff}, diffs...)
+	}
+	if len(diffs) > 0 {
+		assert.Fail(t, "Outcome not as expected:\n"+
+			strings.Join(diffs, "\n")+"\n"+helperReport(tt, status))
+	}
+}
+
+// logMatcher returns the LogMatcher for the case: MatchLogs if set, and
+// otherwise Lines with a LineMatcher for each of WantLogs according to
+// LogMatch.
+func (c HelperCase[In]) logMatcher() LogMatcher {
+
+	if c.MatchLogs != nil {
+		return c.MatchLogs
+	}
+	lines := make([]LineMatcher, len(c.WantLogs))
+	for i, s := range c.WantLogs {
+		switch c.LogMatch {
+		case MatchRegexp:
+			lines[i] = Regexp(s)
+		case MatchContains:
+			lines[i] = Contains(s)
+		default:
+			lines[i] = Exactly(s)
+		}
+	}
+	return Lines(lines...)
+}
@@ -0,0 +1,95 @@
+// cases_test.go
+
+package testig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+// checkEven is our helper under test, with every kind of outcome.
+func checkEven(t testig.TT, n int) {
+	switch {
+	case n < 0:
+		panic("negative")
+	case n == 0:
+		t.Skip("zero")
+	case n%2 != 0:
+		t.Fatalf("odd: %d", n)
+	default:
+		t.Logf("even: %d", n)
+	}
+}
+
+func Test_LogMatch_String(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.Equal("exact", testig.MatchExact.String())
+	assert.Equal("regexp", testig.MatchRegexp.String())
+	assert.Equal("contains", testig.MatchContains.String())
+	assert.Equal("LogMatch(99)", testig.LogMatch(99).String())
+
+}
+
+func Test_HelperCases_Success(t *testing.T) {
+
+	ok := testig.HelperCases(t, checkEven, []testig.HelperCase[int]{
+		{Name: "even", In: 2, WantLogs: []string{"even: 2"}},
+		{Name: "odd", In: 3, WantFail: true, WantStop: true,
+			WantLogs: []string{`^odd: \d$`}, LogMatch: testig.MatchRegexp},
+		{Name: "zero", In: 0, WantSkip: true, WantStop: true,
+			WantLogs: []string{"ze"}, LogMatch: testig.MatchContains},
+		{Name: "negative", In: -1, WantFail: true, WantPanic: true},
+		{Name: "matcher", In: 5, WantFail: true, WantStop: true,
+			MatchLogs: testig.InOrder(testig.Regexp(`^odd`))},
+		{Name: "precedence", In: 2, WantLogs: []string{"ignored"},
+			MatchLogs: testig.Lines(testig.Exactly("even: 2"))},
+	})
+	assert.True(t, ok, "returns true")
+
+}
+
+func Test_HelperCases_Failure(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewNamedTestTester("TestCases")
+	ok := testig.HelperCases(tt, checkEven, []testig.HelperCase[int]{
+		{Name: "fine", In: 4, WantLogs: []string{"even: 4"}},
+		{Name: "wrong flags", In: 3, WantLogs: []string{"odd: 3"}},
+		{Name: "wrong logs", In: 2,
+			WantLogs: []string{"odd"}, LogMatch: testig.MatchContains},
+	})
+	assert.False(ok, "returns false")
+	assert.True(tt.Failed(), "tester Failed")
+
+	subs := tt.Subtests()
+	if !assert.Equal(3, len(subs), "one subtest per case") {
+		return
+	}
+	assert.Equal("TestCases/wrong_flags", subs[1].Name(), "named for case")
+	assert.False(subs[0].Failed(), "first case passed")
+
+	if assert.Equal(1, len(subs[1].Logs), "one failure for all mismatches") {
+		msg := subs[1].Logs[0]
+		assert.Contains(msg, "Outcome not as expected:")
+		assert.Contains(msg, "Failed:   expected false, actual true")
+		assert.Contains(msg, "Stopped:  expected false, actual true")
+		assert.NotContains(msg, "Logs:", "logs as expected")
+		assert.Contains(msg, "status: stopped")
+		assert.Contains(msg, "--- FAIL: TestCases/wrong_flags")
+	}
+	if assert.Equal(1, len(subs[2].Logs), "one failure for logs") {
+		msg := subs[2].Logs[0]
+		assert.Contains(msg, "Logs:     not matched")
+		assert.Contains(msg, "  expected, one per log (1, actual 1):")
+		assert.Contains(msg, `    ! contains "odd" (not matched)`)
+		assert.Contains(msg, `      0: "even: 2"`)
+		assert.NotContains(msg, "Failed:", "flags as expected")
+	}
+
+}
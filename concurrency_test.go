@@ -0,0 +1,122 @@
+// concurrency_test.go -- best run with -race.
+
+package testig_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+func Test_TestTester_ConcurrentUse(t *testing.T) {
+
+	assert := assert.New(t)
+
+	const workers = 20
+
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				t.(testig.TT2).Helper()
+				t.Logf("worker %d", i)
+				if i%2 == 0 {
+					t.Errorf("worker %d failed", i)
+				}
+				t.Failed()
+				t.Skipped()
+				t.(testig.TT2).TempDir()
+				t.(testig.TT2).Context()
+				t.(testig.TT2).Cleanup(func() {})
+			}(i)
+		}
+		wg.Wait()
+	})
+	assert.Equal(workers+workers/2, len(tt.Logs), "all logs recorded")
+	assert.Equal(workers+workers/2, len(tt.Events), "all events recorded")
+	assert.True(tt.Failed(), "Failed returns true")
+	assert.Empty(tt.Misuses(), "no misuse detected")
+
+}
+
+func Test_TestTester_ConcurrentSubtests(t *testing.T) {
+
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				testig.RunSubtest(t, "sub", func(t testig.TT) {
+					t.Error("failed")
+				})
+			}()
+		}
+		wg.Wait()
+	})
+	assert.Equal(t, 10, len(tt.Subtests()), "all subtests recorded")
+	assert.True(t, tt.Failed(), "Failed returns true")
+
+}
+
+func Test_TestTester_DetectMisuse_FailNowInGoroutine(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	tt.DetectMisuse = true
+	reached := false
+	tt.RunHelper(func(t testig.TT) {
+		done := make(chan bool)
+		go func() {
+			defer close(done)
+			t.Fatal("from the wrong goroutine")
+		}()
+		<-done
+		reached = true
+	})
+	assert.True(reached, "test goroutine not stopped, as with testing.T")
+	assert.True(tt.Failed(), "Failed returns true")
+	if assert.Equal(1, len(tt.Misuses()), "one misuse") {
+		assert.Regexp("^FailNow or SkipNow called from goroutine \\d+, "+
+			"not test goroutine \\d+$", tt.Misuses()[0])
+	}
+
+}
+
+func Test_TestTester_DetectMisuse_LogAfterCompletion(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewNamedTestTester("TestFoo")
+	tt.DetectMisuse = true
+	var late testig.TT
+	tt.Run("sub", func(t testig.TT) {
+		late = t
+	})
+	assert.False(tt.Failed(), "not failed yet")
+
+	late.Log("too late")
+	assert.True(late.Failed(), "sub Failed after late Log")
+	assert.Equal([]string{
+		"Log in goroutine after TestFoo/sub has completed: too late",
+	}, late.(*testig.TestTester).Misuses(), "misuse recorded")
+
+}
+
+func Test_TestTester_NoDetectMisuse(t *testing.T) {
+
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {})
+	tt.Log("after the fact")
+	assert.False(t, tt.Failed(), "late Log ignored without DetectMisuse")
+	assert.Empty(t, tt.Misuses(), "no misuse recorded")
+
+}
@@ -0,0 +1,318 @@
+// conformance_test.go -- comparing the TestTester to testing.T.
+
+package testig_test
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+// conformanceEnv is set in the environment of the child process in which
+// Test_Conformance_Child runs the scenarios on a real testing.T.
+const conformanceEnv = "TESTIG_CONFORMANCE_CHILD"
+
+// conformanceRoot is the name of the test under which the scenarios run, both
+// in the child process and in the TestTester.
+const conformanceRoot = "Test_Conformance_Child"
+
+// scenario is a helper run as a subtest both of a real testing.T and of a
+// TestTester.  It is the same code on the same source lines either way, so
+// the outcomes and output should be identical.
+type scenario struct {
+	name string
+	f    func(testig.TT)
+}
+
+// battery is a group of scenarios compared in one child process.
+type battery struct {
+	name      string
+	scenarios []scenario
+}
+
+// tt2 returns t as a TT2, for scenarios.
+func tt2(t testig.TT) testig.TT2 {
+	return t.(testig.TT2)
+}
+
+var batteries = []battery{
+	{"log", []scenario{
+		{"plain", func(t testig.TT) { t.Log("plain") }},
+		{"operands", func(t testig.TT) { t.Log("a", "b", 1, 2, true, nil, 1.5) }},
+		{"no args", func(t testig.TT) { t.Log() }},
+		{"trailing newline", func(t testig.TT) { t.Log("trailing\n") }},
+		{"trailing newlines", func(t testig.TT) { t.Log("trailing\n\n") }},
+		{"newline", func(t testig.TT) { t.Log("\n") }},
+		{"multi-line", func(t testig.TT) { t.Log("one\ntwo\n  three") }},
+		{"percent", func(t testig.TT) { t.Log("100%", "%d") }},
+		{"logf", func(t testig.TT) { t.Logf("%d%% of %q", 5, "x") }},
+		{"logf newline", func(t testig.TT) { t.Logf("line\n") }},
+		{"logf newlines", func(t testig.TT) { t.Logf("line\n\n") }},
+		{"logf empty", func(t testig.TT) { t.Logf("") }},
+		{"values", func(t testig.TT) {
+			t.Log([]string{"a", "b"}, map[string]int{"k": 1}, struct{ A int }{2})
+		}},
+		{"error", func(t testig.TT) { t.Error("bad\nworse\n") }},
+		{"errorf", func(t testig.TT) { t.Errorf("%s\n", "bad") }},
+		{"fatal", func(t testig.TT) { t.Fatal("fatal", 1) }},
+		{"skip", func(t testig.TT) { t.Skip("later\n") }},
+	}},
+	{"stop", []scenario{
+		{"log after FailNow", func(t testig.TT) {
+			t.Log("before")
+			t.FailNow()
+			t.Log("unreached")
+		}},
+		{"log after SkipNow", func(t testig.TT) {
+			t.SkipNow()
+			t.Log("unreached")
+		}},
+		{"skip after fail", func(t testig.TT) {
+			t.Fail()
+			t.Skip("skipped")
+		}},
+		{"skip after error", func(t testig.TT) {
+			t.Error("bad")
+			t.Skipf("skipped %d", 1)
+		}},
+		{"fatal after skip check", func(t testig.TT) {
+			if !t.Skipped() {
+				t.Fatalf("not skipped, failed %v", t.Failed())
+			}
+		}},
+		{"errors continue", func(t testig.TT) {
+			t.Error("one")
+			t.Errorf("two")
+			t.Fail()
+			t.Log("failed:", t.Failed())
+		}},
+		{"stop in helper", func(t testig.TT) {
+			stopHelper(t)
+			t.Log("unreached")
+		}},
+	}},
+	{"helper", []scenario{
+		{"marked", func(t testig.TT) { logHelper(t, "from helper") }},
+		{"nested", func(t testig.TT) { outerHelper(t) }},
+		{"unmarked", func(t testig.TT) { unmarkedHelper(t) }},
+		{"name", func(t testig.TT) { t.Log(tt2(t).Name()) }},
+	}},
+	{"cleanup", []scenario{
+		{"order", func(t testig.TT) {
+			tt2(t).Cleanup(func() { t.Log("registered first") })
+			tt2(t).Cleanup(func() { t.Log("registered second") })
+			t.Log("body")
+		}},
+		{"after FailNow", func(t testig.TT) {
+			tt2(t).Cleanup(func() { t.Log("cleaned up") })
+			t.FailNow()
+		}},
+		{"error in cleanup", func(t testig.TT) {
+			tt2(t).Cleanup(func() { t.Error("in cleanup") })
+		}},
+		{"skip in cleanup", func(t testig.TT) {
+			tt2(t).Cleanup(func() { t.Skip("in cleanup") })
+			t.Log("body")
+		}},
+	}},
+	{"subtest", []scenario{
+		{"failure propagates", func(t testig.TT) {
+			ok := testig.RunSubtest(t, "inner", func(t testig.TT) {
+				t.Error("inner bad")
+			})
+			t.Log("parent continues, ok:", ok, "failed:", t.Failed())
+		}},
+		{"fatal in subtest", func(t testig.TT) {
+			testig.RunSubtest(t, "inner", func(t testig.TT) {
+				t.Fatal("inner fatal")
+			})
+			t.Log("parent continues")
+		}},
+		{"skipped subtest", func(t testig.TT) {
+			ok := testig.RunSubtest(t, "inner", func(t testig.TT) {
+				t.Skip("inner skip")
+			})
+			t.Log("ok:", ok, "skipped:", t.Skipped())
+		}},
+		{"skip after failed subtest", func(t testig.TT) {
+			testig.RunSubtest(t, "inner", func(t testig.TT) { t.Fail() })
+			t.Skip("parent skip")
+		}},
+		{"nested", func(t testig.TT) {
+			testig.RunSubtest(t, "a", func(t testig.TT) {
+				t.Log("in a")
+				testig.RunSubtest(t, "b", func(t testig.TT) {
+					t.Log("in b")
+				})
+				t.Log("back in a")
+			})
+			t.Log("back in parent")
+		}},
+		{"duplicate names", func(t testig.TT) {
+			for i := 0; i < 3; i++ {
+				testig.RunSubtest(t, "dup", func(t testig.TT) {
+					t.Log(tt2(t).Name())
+				})
+			}
+		}},
+		{"parallel", func(t testig.TT) {
+			// Only one, as go test resumes paused subtests in no set order.
+			testig.RunSubtest(t, "p", func(t testig.TT) {
+				t.Log("before Parallel")
+				testig.Parallel(t)
+				t.Log("after Parallel")
+			})
+			testig.RunSubtest(t, "serial", func(t testig.TT) {
+				t.Log("serial")
+			})
+			t.Log("parent done")
+		}},
+	}},
+}
+
+func logHelper(t testig.TT, msg string) {
+	tt2(t).Helper()
+	t.Log(msg)
+}
+
+func outerHelper(t testig.TT) {
+	tt2(t).Helper()
+	logHelper(t, "from nested helper")
+}
+
+func unmarkedHelper(t testig.TT) {
+	t.Log("from unmarked helper")
+}
+
+func stopHelper(t testig.TT) {
+	tt2(t).Helper()
+	t.Fatal("stopped in helper")
+}
+
+func Test_Conformance_Child(t *testing.T) {
+
+	if os.Getenv(conformanceEnv) == "" {
+		t.Skip("run in a child process by Test_Conformance")
+	}
+	for _, b := range batteries {
+		t.Run(b.name, func(t *testing.T) {
+			for _, s := range b.scenarios {
+				t.Run(s.name, func(t *testing.T) { s.f(t) })
+			}
+		})
+	}
+
+}
+
+// durationRegexp matches the durations in test footers.
+var durationRegexp = regexp.MustCompile(`\(\d+\.\d+s\)`)
+
+// childOutput runs the battery called name in a child process and returns
+// its output up to the package-level PASS or FAIL, with durations zeroed.
+func childOutput(t *testing.T, name string, verbose bool) string {
+
+	args := []string{
+		"-test.run=^" + conformanceRoot + "$/^" + regexp.QuoteMeta(name) + "$",
+		"-test.parallel=1",
+	}
+	if verbose {
+		args = append(args, "-test.v")
+	}
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), conformanceEnv+"=1")
+	out, err := cmd.Output()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		t.Fatalf("running child: %v", err)
+	}
+
+	lines := strings.SplitAfter(string(out), "\n")
+	for i, line := range lines {
+		if line == "PASS\n" || line == "FAIL\n" {
+			lines = lines[:i]
+			break
+		}
+	}
+	return durationRegexp.ReplaceAllString(strings.Join(lines, ""), "(0.00s)")
+}
+
+// runBattery runs the battery under a TestTester tree shaped like that of the
+// child process.
+func runBattery(b battery) *testig.TestTester {
+
+	tt := testig.NewNamedTestTester(conformanceRoot)
+	tt.MaxParallel = 1
+	tt.RunHelper(func(t testig.TT) {
+		testig.RunSubtest(t, b.name, func(t testig.TT) {
+			for _, s := range b.scenarios {
+				testig.RunSubtest(t, s.name, s.f)
+			}
+		})
+	})
+	return tt
+}
+
+// footerRegexp matches a footer in go test -v output.
+var footerRegexp = regexp.MustCompile(`(?m)^\s*--- (PASS|FAIL|SKIP): (\S+)`)
+
+// outcomes returns the sorted "RESULT: name" of every test reported in the
+// verbose output.
+func outcomes(out string) []string {
+
+	res := []string{}
+	for _, m := range footerRegexp.FindAllStringSubmatch(out, -1) {
+		res = append(res, m[1]+": "+m[2])
+	}
+	sort.Strings(res)
+	return res
+}
+
+// treeOutcomes returns the sorted "RESULT: name" of tt and all its subtests,
+// as in outcomes, but from their Failed and Skipped methods.
+func treeOutcomes(tt *testig.TestTester) []string {
+
+	res := "PASS"
+	if tt.Failed() {
+		res = "FAIL"
+	} else if tt.Skipped() {
+		res = "SKIP"
+	}
+	all := []string{res + ": " + tt.Name()}
+	for _, sub := range tt.Subtests() {
+		all = append(all, treeOutcomes(sub)...)
+	}
+	sort.Strings(all)
+	return all
+}
+
+func Test_Conformance(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("runs child processes")
+	}
+
+	for _, b := range batteries {
+		t.Run(b.name, func(t *testing.T) {
+
+			assert := assert.New(t)
+
+			tt := runBattery(b)
+			verbose := childOutput(t, b.name, true)
+			assert.Equal(outcomes(verbose), treeOutcomes(tt),
+				"outcomes match testing.T")
+			assert.Equal(verbose,
+				durationRegexp.ReplaceAllString(tt.Output(true), "(0.00s)"),
+				"verbose output matches testing.T")
+			assert.Equal(childOutput(t, b.name, false),
+				durationRegexp.ReplaceAllString(tt.Output(false), "(0.00s)"),
+				"plain output matches testing.T")
+		})
+	}
+
+}
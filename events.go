@@ -0,0 +1,228 @@
+// events.go -- structured log events recorded by the TestTester.
+
+package testig
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventKind identifies the kind of call that produced an Event.
+type EventKind int
+
+const (
+	// EventLog is recorded by Log and Logf.
+	EventLog EventKind = iota
+	// EventError is recorded by Error and Errorf.
+	EventError
+	// EventFatal is recorded by Fatal and Fatalf.
+	EventFatal
+	// EventSkip is recorded by Skip, Skipf and SkipNow.
+	EventSkip
+	// EventFail is recorded by Fail and FailNow.
+	EventFail
+)
+
+// String stringifies the EventKind.
+func (k EventKind) String() string {
+	switch k {
+	case EventLog:
+		return "Log"
+	case EventError:
+		return "Error"
+	case EventFatal:
+		return "Fatal"
+	case EventSkip:
+		return "Skip"
+	case EventFail:
+		return "Fail"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+}
+
+// Event is a single call to a TT method that logged something or changed the
+// state of the test.  Each call records exactly one Event, so for instance
+// Fatal records an EventFatal but no separate EventLog or EventFail.
+//
+// File and Line give the source location as testing.T would report it,
+// that is the location of the call, skipping any functions marked with
+// Helper.
+//
+// The Message is formatted as testing.T formats it: with fmt.Sprintln for
+// Log, Error, Fatal and Skip, or fmt.Sprintf for their f variants, and then
+// with one trailing newline, if any, removed.  Thus Log("foo") and
+// Logf("foo\n") both record "foo", while Log("foo\n") records "foo\n",
+// which testing.T prints as two lines, the second empty.
+type Event struct {
+	Kind      EventKind
+	Message   string
+	Logged    bool // false for Fail, FailNow and SkipNow
+	File      string
+	Line      int
+	Time      time.Time
+	Goroutine int64
+	Stack     []StackFrame // for failures only; see Attribution
+}
+
+// Failure reports whether the Event marked the test as failed, that is if it
+// is an EventError, EventFatal or EventFail.
+func (e Event) Failure() bool {
+	return e.Kind == EventError || e.Kind == EventFatal || e.Kind == EventFail
+}
+
+// String stringifies the Event in a format similar to that of testing.T
+// output, prefixed with the Kind.
+func (e Event) String() string {
+	return fmt.Sprintf("%s %s:%d: %s",
+		e.Kind, filepath.Base(e.File), e.Line, e.Message)
+}
+
+// testerPrefix is the function-name prefix of methods on the package's own
+// pointer types, notably the TestTester, which are never reported as the
+// location of an event.
+var testerPrefix = reflect.TypeOf(TestTester{}).PkgPath() + ".(*"
+
+// ownDir is the directory of the package's source files.
+var ownDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// isOwnFrame reports whether frame is in one of the methods of the package's
+// own pointer types.  When such a method is inlined, closures within it are
+// named for the caller, but still have its file.
+func isOwnFrame(frame runtime.Frame) bool {
+	if strings.HasPrefix(frame.Function, testerPrefix) {
+		return true
+	}
+	return strings.Contains(frame.Function, ".(*") &&
+		filepath.Dir(frame.File) == ownDir &&
+		!strings.HasSuffix(frame.File, "_test.go")
+}
+
+// record records an Event and, if logged, its message in Logs; and marks the
+// test as failed or skipped as appropriate for the kind.
+func (tt *TestTester) record(kind EventKind, msg string, logged bool) {
+
+	stack := tt.callStack()
+	msg = strings.TrimSuffix(msg, "\n")
+	ev := Event{
+		Kind:      kind,
+		Message:   msg,
+		Logged:    logged,
+		Time:      time.Now(),
+		Goroutine: goroutineID(),
+	}
+	if i := reportedFrame(stack); i >= 0 {
+		ev.File = stack[i].File
+		ev.Line = stack[i].Line
+	}
+	if ev.Failure() {
+		ev.Stack = stack
+	}
+
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if tt.abandoned[ev.Goroutine] {
+		return // left running after a timeout
+	}
+	if tt.DetectMisuse && tt.completed {
+		tt.misuse(fmt.Sprintf("%s in goroutine after %s has completed: %s",
+			kind, tt.name, msg))
+	}
+	tt.Events = append(tt.Events, ev)
+	if logged {
+		tt.Logs = append(tt.Logs, msg)
+	}
+	switch kind {
+	case EventError, EventFatal, EventFail:
+		tt.failed = true
+	case EventSkip:
+		tt.skipped = true
+	}
+}
+
+// callStack returns the frames leading to an event, mirroring the logic of
+// testing.T: frames of the package's own methods are skipped, then every
+// frame up to the runner is returned, each marked if its function was marked
+// with Helper.  For a TestTester not run by RunHelper the frames stop at the
+// testing package.
+func (tt *TestTester) callStack() []StackFrame {
+
+	pc := make([]uintptr, 64)
+	n := runtime.Callers(3, pc) // runtime.Callers, callStack, record
+	frames := runtime.CallersFrames(pc[:n])
+
+	stack := []StackFrame{}
+	for {
+		frame, more := frames.Next()
+		isOwn := isOwnFrame(frame)
+		switch {
+		case len(stack) == 0 && isOwn:
+			// still inside the TestTester
+		case isOwn, strings.HasPrefix(frame.Function, "testing."),
+			frame.Function == "runtime.goexit":
+			// reached the runner
+			return stack
+		default:
+			stack = append(stack, StackFrame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+				Helper:   tt.isHelper(frame.Function),
+			})
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// reportedFrame returns the index of the frame in stack that testing.T would
+// report as the location of an event: the first not marked with Helper, or
+// if all are helpers the last one; or -1 if stack is empty.
+func reportedFrame(stack []StackFrame) int {
+	for i, frame := range stack {
+		if !frame.Helper {
+			return i
+		}
+	}
+	return len(stack) - 1
+}
+
+// isHelper reports whether the function fn was marked with Helper on tt or
+// any of its parents.
+func (tt *TestTester) isHelper(fn string) bool {
+	for t := tt; t != nil; t = t.parent {
+		t.mu.RLock()
+		found := t.helpers[fn]
+		t.mu.RUnlock()
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// goroutineID returns the ID of the current goroutine, as seen in stack
+// traces, or zero if it can not be determined.
+func goroutineID() int64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i > 0 {
+		id, err := strconv.ParseInt(string(buf[:i]), 10, 64)
+		if err == nil {
+			return id
+		}
+	}
+	return 0
+}
@@ -0,0 +1,158 @@
+// events_test.go
+
+package testig_test
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+// here returns the line from which it was called.
+func here() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func Test_EventKind_String(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.Equal("Log", testig.EventLog.String())
+	assert.Equal("Error", testig.EventError.String())
+	assert.Equal("Fatal", testig.EventFatal.String())
+	assert.Equal("Skip", testig.EventSkip.String())
+	assert.Equal("Fail", testig.EventFail.String())
+	assert.Equal("EventKind(99)", testig.EventKind(99).String())
+
+}
+
+func Test_Event_String(t *testing.T) {
+
+	ev := testig.Event{
+		Kind:    testig.EventError,
+		Message: "oops",
+		File:    "/some/where/foo_test.go",
+		Line:    12,
+	}
+	assert.Equal(t, "Error foo_test.go:12: oops", ev.String())
+
+}
+
+func Test_TestTester_Events(t *testing.T) {
+
+	assert := assert.New(t)
+
+	start := time.Now()
+	tt := testig.NewTestTester()
+	var lines []int
+	tt.RunHelper(func(t testig.TT) {
+		t.Log("log")
+		lines = append(lines, here()-1)
+		t.Errorf("error %d", 1)
+		lines = append(lines, here()-1)
+		t.Fail()
+		lines = append(lines, here()-1)
+		t.Skip("skip")
+		lines = append(lines, here()-1)
+	})
+
+	kinds := []testig.EventKind{}
+	for i, ev := range tt.Events {
+		kinds = append(kinds, ev.Kind)
+		assert.Equal("events_test.go", filepath.Base(ev.File), "file")
+		if i < len(lines) {
+			assert.Equal(lines[i], ev.Line, "line")
+		}
+		assert.False(ev.Time.Before(start), "time set")
+		assert.NotZero(ev.Goroutine, "goroutine set")
+	}
+	assert.Equal([]testig.EventKind{testig.EventLog, testig.EventError,
+		testig.EventFail, testig.EventSkip}, kinds, "kinds recorded")
+	assert.Equal("error 1", tt.Events[1].Message, "message recorded")
+	assert.False(tt.Events[2].Logged, "Fail not logged")
+	assert.Equal([]string{"log", "error 1", "skip"}, tt.Logs,
+		"Logs derived from logged events")
+
+}
+
+func Test_TestTester_Events_Fatal(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {
+		t.Fatal("fatal")
+	})
+	if assert.Equal(1, len(tt.Events), "one event") {
+		assert.Equal(testig.EventFatal, tt.Events[0].Kind, "Fatal kind")
+		assert.True(tt.Events[0].Logged, "Fatal logged")
+	}
+
+}
+
+func Test_TestTester_Events_Helper(t *testing.T) {
+
+	assert := assert.New(t)
+
+	inner := func(t testig.TT2) {
+		t.Helper()
+		t.Error("from inner")
+	}
+	outer := func(t testig.TT2) {
+		t.Helper()
+		inner(t)
+	}
+	unmarkedLine := here() + 2
+	unmarked := func(t testig.TT2) {
+		t.Error("from unmarked")
+	}
+
+	var called, subCalled int
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {
+		outer(t.(testig.TT2))
+		called = here() - 1
+		testig.RunSubtest(t, "sub", func(t testig.TT) {
+			// helpers marked on the parent count here too
+			inner(t.(testig.TT2))
+			subCalled = here() - 1
+		})
+		unmarked(t.(testig.TT2))
+	})
+
+	if assert.Equal(2, len(tt.Events), "two events") {
+		assert.Equal(called, tt.Events[0].Line, "helpers skipped")
+		assert.Equal(unmarkedLine, tt.Events[1].Line,
+			"unmarked function reported")
+	}
+	sub := tt.Subtests()[0]
+	if assert.Equal(1, len(sub.Events), "one event in sub") {
+		assert.Equal(subCalled, sub.Events[0].Line,
+			"parent's helpers skipped in sub")
+	}
+
+}
+
+func Test_TestTester_Events_AllHelpers(t *testing.T) {
+
+	helper := func(t testig.TT2) {
+		t.Helper()
+		t.Log("all alone")
+	}
+
+	// When everything is a helper, the outermost one is reported.
+	tt := testig.NewTestTester()
+	line := here() + 3
+	tt.RunHelper(func(t testig.TT) {
+		t.(testig.TT2).Helper()
+		helper(t.(testig.TT2))
+	})
+	assert.Equal(t, line, tt.Events[0].Line, "outermost helper reported")
+
+}
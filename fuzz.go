@@ -0,0 +1,259 @@
+// fuzz.go -- testing fuzz helpers.
+
+package testig
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// FF defines an interface implemented by both the FuzzTester and testing.F,
+// for fuzz helper functions such as seed corpus adders:
+//
+//	func AddSeeds(f FF) {
+//	    f.Add("hello", 1)
+//	    f.Add("world", 2)
+//	}
+//
+// Fuzz targets for a testing.F must take a *testing.T and those for a
+// FuzzTester a TT, so helpers that wrap fuzz targets should write them with
+// a TT argument and pass them to the package-level Fuzz function.
+type FF interface {
+	TT2
+	Add(args ...interface{})
+	Fuzz(ff interface{})
+}
+
+// supportedFuzzTypes are the argument types supported by testing.F.
+var supportedFuzzTypes = map[reflect.Type]bool{
+	reflect.TypeOf([]byte(nil)): true,
+	reflect.TypeOf(""):          true,
+	reflect.TypeOf(false):       true,
+	reflect.TypeOf(byte(0)):     true,
+	reflect.TypeOf(rune(0)):     true,
+	reflect.TypeOf(float32(0)):  true,
+	reflect.TypeOf(float64(0)):  true,
+	reflect.TypeOf(int(0)):      true,
+	reflect.TypeOf(int8(0)):     true,
+	reflect.TypeOf(int16(0)):    true,
+	reflect.TypeOf(int32(0)):    true,
+	reflect.TypeOf(int64(0)):    true,
+	reflect.TypeOf(uint(0)):     true,
+	reflect.TypeOf(uint8(0)):    true,
+	reflect.TypeOf(uint16(0)):   true,
+	reflect.TypeOf(uint32(0)):   true,
+	reflect.TypeOf(uint64(0)):   true,
+}
+
+var (
+	testingTType     = reflect.TypeOf((*testing.T)(nil))
+	ttInterfaceTypes = []reflect.Type{
+		reflect.TypeOf((*TT)(nil)).Elem(),
+		reflect.TypeOf((*TT2)(nil)).Elem(),
+	}
+)
+
+// Fuzz calls f.Fuzz with the fuzz target ff, whose first argument may be a
+// TT or TT2 even if f is a *testing.F: in that case ff is wrapped in a
+// function taking a *testing.T.  It is the FF-friendly way to call f.Fuzz:
+//
+//	func FuzzHelper(f FF, check func(string) error) {
+//	    Fuzz(f, func(t TT, s string) {
+//	        if err := check(s); err != nil {
+//	            t.Fatal(err)
+//	        }
+//	    })
+//	}
+func Fuzz(f FF, ff interface{}) {
+
+	fn := reflect.ValueOf(ff)
+	if tf, ok := f.(*testing.F); ok && fn.Kind() == reflect.Func &&
+		fn.Type().NumIn() > 0 && isTTInterface(fn.Type().In(0)) {
+
+		in := make([]reflect.Type, fn.Type().NumIn())
+		in[0] = testingTType
+		for i := 1; i < len(in); i++ {
+			in[i] = fn.Type().In(i)
+		}
+		out := make([]reflect.Type, fn.Type().NumOut())
+		for i := range out {
+			out[i] = fn.Type().Out(i)
+		}
+		wrapped := reflect.MakeFunc(reflect.FuncOf(in, out, false),
+			func(args []reflect.Value) []reflect.Value {
+				args[0] = args[0].Convert(fn.Type().In(0))
+				return fn.Call(args)
+			})
+		tf.Fuzz(wrapped.Interface())
+		return
+	}
+	f.Fuzz(ff)
+}
+
+// isTTInterface reports whether t is one of the TT interfaces.
+func isTTInterface(t reflect.Type) bool {
+	for _, it := range ttInterfaceTypes {
+		if t == it {
+			return true
+		}
+	}
+	return false
+}
+
+// FuzzInput is a single seed corpus entry as run by a FuzzTester.
+type FuzzInput struct {
+	Values []interface{}
+	Tester *TestTester
+	Status RunStatus
+}
+
+// Failed reports whether the fuzz target failed for the input.
+func (fi *FuzzInput) Failed() bool {
+	return fi.Tester.Failed()
+}
+
+// FuzzTester implements the FF interface in a way that helps us test our
+// fuzz helper functions.  It embeds a TestTester, which records the usual TT
+// calls.
+//
+// Run a fuzz helper with RunFuzz, then inspect the Corpus it added to and,
+// if it called Fuzz, the Inputs on which the fuzz target was run.  Only the
+// seed corpus is used: there is no actual fuzzing.
+type FuzzTester struct {
+	*TestTester
+	Corpus     [][]interface{} // from Add
+	Inputs     []*FuzzInput    // from Fuzz
+	fuzzCalled bool
+}
+
+// DefaultFuzzTesterName is the name given to FuzzTesters created by
+// NewFuzzTester.
+const DefaultFuzzTesterName = "FuzzTester"
+
+// NewFuzzTester returns a new FuzzTester ready for testing fuzz helpers.
+func NewFuzzTester() *FuzzTester {
+	return &FuzzTester{
+		TestTester: NewNamedTestTester(DefaultFuzzTesterName),
+		Corpus:     [][]interface{}{},
+		Inputs:     []*FuzzInput{},
+	}
+}
+
+// RunFuzz runs the fuzz helper function f with the FuzzTester as its
+// argument, in the same way as RunHelper: in its own goroutine, stopping for
+// real on FailNow or SkipNow.
+func (ft *FuzzTester) RunFuzz(f func(FF)) RunStatus {
+	return ft.run(func() { f(ft) })
+}
+
+// FailedInputs returns those Inputs for which the fuzz target failed.
+func (ft *FuzzTester) FailedInputs() []*FuzzInput {
+	failed := []*FuzzInput{}
+	for _, fi := range ft.Inputs {
+		if fi.Failed() {
+			failed = append(failed, fi)
+		}
+	}
+	return failed
+}
+
+// Add mirrors the same-named function in testing.F: it adds args to the seed
+// corpus, panicking if any of them is of a type not supported for fuzzing.
+func (ft *FuzzTester) Add(args ...interface{}) {
+	values := []interface{}{}
+	for _, arg := range args {
+		if t := reflect.TypeOf(arg); !supportedFuzzTypes[t] {
+			panic(fmt.Sprintf("testing: unsupported type to Add %v", t))
+		}
+		values = append(values, arg)
+	}
+	ft.mu.Lock()
+	ft.Corpus = append(ft.Corpus, values)
+	ft.mu.Unlock()
+}
+
+// Fuzz mirrors the same-named function in testing.F, except that the first
+// argument of the fuzz target ff must be a TT (or TT2) instead of a
+// *testing.T.  As with testing.F it panics if ff is not a valid fuzz target,
+// and calls Fatal if any seed corpus entry does not match its arguments.
+//
+// The target is then run over each entry in the seed corpus, as a subtest
+// named seed#N with its own TestTester, and each run is recorded in Inputs.
+func (ft *FuzzTester) Fuzz(ff interface{}) {
+
+	ft.mu.Lock()
+	called := ft.fuzzCalled
+	ft.fuzzCalled = true
+	ft.mu.Unlock()
+	if called {
+		panic("testing: F.Fuzz called more than once")
+	}
+	if ft.Failed() {
+		return
+	}
+
+	fn := reflect.ValueOf(ff)
+	fnType := fn.Type()
+	if fnType.Kind() != reflect.Func {
+		panic("testing: F.Fuzz must receive a function")
+	}
+	if fnType.NumIn() < 2 || !isTTInterface(fnType.In(0)) {
+		panic("testig: fuzz target must receive at least two arguments, " +
+			"where the first argument is a TT")
+	}
+	if fnType.NumOut() != 0 {
+		panic("testing: fuzz target must not return a value")
+	}
+	types := []reflect.Type{}
+	for i := 1; i < fnType.NumIn(); i++ {
+		t := fnType.In(i)
+		if !supportedFuzzTypes[t] {
+			panic(fmt.Sprintf("testing: unsupported type for fuzzing %v", t))
+		}
+		types = append(types, t)
+	}
+
+	for _, values := range ft.Corpus {
+		if err := checkCorpusEntry(values, types); err != nil {
+			ft.Fatal(err)
+			return
+		}
+	}
+
+	for i, values := range ft.Corpus {
+		args := make([]reflect.Value, len(values)+1)
+		for j, v := range values {
+			args[j+1] = reflect.ValueOf(v)
+		}
+		fi := &FuzzInput{Values: values}
+		fi.Tester, fi.Status = ft.subtest(fmt.Sprintf("seed#%d", i),
+			func(t TT) {
+				args[0] = reflect.ValueOf(t).Convert(fnType.In(0))
+				fn.Call(args)
+			})
+		ft.mu.Lock()
+		ft.Inputs = append(ft.Inputs, fi)
+		ft.mu.Unlock()
+	}
+}
+
+// checkCorpusEntry returns an error, worded as for testing.F, if values do not
+// match types.
+func checkCorpusEntry(values []interface{}, types []reflect.Type) error {
+	if len(values) != len(types) {
+		return fmt.Errorf("wrong number of values in corpus entry: %d, want %d",
+			len(values), len(types))
+	}
+	valueTypes := make([]reflect.Type, len(values))
+	for i := range values {
+		valueTypes[i] = reflect.TypeOf(values[i])
+	}
+	for i := range types {
+		if valueTypes[i] != types[i] {
+			return fmt.Errorf("mismatched types in corpus entry: %v, want %v",
+				valueTypes, types)
+		}
+	}
+	return nil
+}
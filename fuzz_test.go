@@ -0,0 +1,173 @@
+// fuzz_test.go
+
+package testig_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+// Both implement FF.
+var _ testig.FF = (*testing.F)(nil)
+var _ testig.FF = (*testig.FuzzTester)(nil)
+
+// addSeeds and fuzzNoX are our fuzz helpers under test.
+func addSeeds(f testig.FF) {
+	f.Add("hello", 1)
+	f.Add("xylophone", 2)
+}
+
+func fuzzNoX(f testig.FF) {
+	testig.Fuzz(f, func(t testig.TT, s string, n int) {
+		if strings.Contains(s, "x") {
+			t.Fatalf("found x in %q", s)
+		}
+	})
+}
+
+func Test_NewFuzzTester(t *testing.T) {
+
+	assert := assert.New(t)
+
+	ft := testig.NewFuzzTester()
+	assert.Equal(testig.DefaultFuzzTesterName, ft.Name(), "Name set")
+	assert.Equal([][]interface{}{}, ft.Corpus, "Corpus empty")
+	assert.Equal([]*testig.FuzzInput{}, ft.Inputs, "Inputs empty")
+
+}
+
+func Test_FuzzTester_RunFuzz(t *testing.T) {
+
+	assert := assert.New(t)
+
+	ft := testig.NewFuzzTester()
+	status := ft.RunFuzz(func(f testig.FF) {
+		addSeeds(f)
+		fuzzNoX(f)
+	})
+	assert.Equal(testig.RunReturned, status, "returned")
+	assert.Equal([][]interface{}{{"hello", 1}, {"xylophone", 2}}, ft.Corpus,
+		"seeds added")
+	assert.True(ft.Failed(), "input failure propagated")
+
+	if assert.Equal(2, len(ft.Inputs), "two inputs run") {
+		assert.False(ft.Inputs[0].Failed(), "first input passed")
+		assert.Equal(testig.RunReturned, ft.Inputs[0].Status,
+			"first input returned")
+		assert.Equal("FuzzTester/seed#0", ft.Inputs[0].Tester.Name(),
+			"named as seed")
+		assert.True(ft.Inputs[1].Failed(), "second input failed")
+		assert.Equal(testig.RunStopped, ft.Inputs[1].Status,
+			"second input stopped")
+		assert.Equal([]string{`found x in "xylophone"`},
+			ft.Inputs[1].Tester.Logs, "logged in input's tester")
+	}
+	failed := ft.FailedInputs()
+	if assert.Equal(1, len(failed), "one failed input") {
+		assert.Equal([]interface{}{"xylophone", 2}, failed[0].Values,
+			"failed values")
+	}
+
+}
+
+func Test_FuzzTester_Fuzz_Panic(t *testing.T) {
+
+	ft := testig.NewFuzzTester()
+	ft.RunFuzz(func(f testig.FF) {
+		f.Add(1)
+		f.Fuzz(func(t testig.TT, n int) { panic("boom") })
+	})
+	if assert.Equal(t, 1, len(ft.Inputs), "one input run") {
+		assert.Equal(t, testig.RunPanicked, ft.Inputs[0].Status, "panicked")
+		assert.Equal(t, "boom", ft.Inputs[0].Tester.Panic, "Panic recorded")
+	}
+
+}
+
+func Test_FuzzTester_Add_Unsupported(t *testing.T) {
+
+	ft := testig.NewFuzzTester()
+	testig.AssertPanicsWith(t, func() { ft.Add("ok", []int{1}) },
+		"testing: unsupported type to Add []int")
+
+}
+
+func Test_FuzzTester_Fuzz_BadTargets(t *testing.T) {
+
+	cases := []struct {
+		target interface{}
+		exp    string
+	}{
+		{"nope", "testing: F.Fuzz must receive a function"},
+		{func(t testig.TT) {},
+			"testig: fuzz target must receive at least two arguments, " +
+				"where the first argument is a TT"},
+		{func(t *testing.T, s string) {},
+			"testig: fuzz target must receive at least two arguments, " +
+				"where the first argument is a TT"},
+		{func(t testig.TT, s string) bool { return true },
+			"testing: fuzz target must not return a value"},
+		{func(t testig.TT2, s []int) {},
+			"testing: unsupported type for fuzzing []int"},
+	}
+	for _, c := range cases {
+		ft := testig.NewFuzzTester()
+		testig.AssertPanicsWith(t, func() { ft.Fuzz(c.target) }, c.exp)
+	}
+
+	ft := testig.NewFuzzTester()
+	ft.Fuzz(func(t testig.TT, s string) {})
+	testig.AssertPanicsWith(t,
+		func() { ft.Fuzz(func(t testig.TT, s string) {}) },
+		"testing: F.Fuzz called more than once")
+
+}
+
+func Test_FuzzTester_Fuzz_CorpusMismatch(t *testing.T) {
+
+	assert := assert.New(t)
+
+	ft := testig.NewFuzzTester()
+	status := ft.RunFuzz(func(f testig.FF) {
+		f.Add("a", "b")
+		f.Fuzz(func(t testig.TT, s string) {})
+	})
+	assert.Equal(testig.RunStopped, status, "stopped")
+	assert.Equal([]string{
+		"wrong number of values in corpus entry: 2, want 1",
+	}, ft.Logs, "count mismatch logged")
+	assert.Empty(ft.Inputs, "no inputs run")
+
+	ft = testig.NewFuzzTester()
+	ft.RunFuzz(func(f testig.FF) {
+		f.Add(int64(1))
+		f.Fuzz(func(t testig.TT, n int) {})
+	})
+	assert.Equal([]string{
+		"mismatched types in corpus entry: [int64], want [int]",
+	}, ft.Logs, "type mismatch logged")
+
+	// Already failed, so Fuzz does nothing.
+	ft = testig.NewFuzzTester()
+	ft.Add(1)
+	ft.Fail()
+	ft.Fuzz(func(t testig.TT, n int) { t.Log("ran") })
+	assert.Empty(ft.Inputs, "no inputs run after failure")
+
+}
+
+func Fuzz_FF_TestingF(f *testing.F) {
+
+	// Make sure the real thing works too, with the wrapped target.
+	addSeeds(f)
+	testig.Fuzz(f, func(t testig.TT, s string, n int) {
+		if n < 1 {
+			t.Fatal("bad n")
+		}
+	})
+
+}
@@ -0,0 +1,80 @@
+// helper.go -- one-shot assertions on test helpers.
+
+package testig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// AssertHelperFails fails with msgAndArgs unless the helper f fails when run
+// with RunHelper on a new TestTester, with at least one logged message (or
+// the recovered panic) matching the regular expression exp, which must
+// compile.  An empty exp matches any failure.  It is safe to omit msgAndArgs.
+// It returns true if the helper failed as expected.
+//
+// The failure message includes the helper's output as go test -v would have
+// printed it.
+func AssertHelperFails(t TT, f func(TT), exp string, msgAndArgs ...interface{}) bool {
+
+	re := regexp.MustCompile(exp)
+	tt := NewTestTester()
+	status := tt.RunHelper(f)
+
+	if !tt.Failed() {
+		return assert.Fail(t,
+			"Helper did not fail:\n"+helperReport(tt, status), msgAndArgs...)
+	}
+	if exp == "" {
+		return true
+	}
+	for _, msg := range tt.Logs {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	if tt.Panic != nil && re.MatchString(fmt.Sprint(tt.Panic)) {
+		return true
+	}
+	errMsg := fmt.Sprintf("Helper failure not as expected:\n"+
+		"  expected: Regexp /%s/\n%s", exp, helperReport(tt, status))
+	return assert.Fail(t, errMsg, msgAndArgs...)
+}
+
+// AssertHelperPasses fails with msgAndArgs unless the helper f passes when run
+// with RunHelper on a new TestTester.  A helper that skips passes.  It is safe
+// to omit msgAndArgs.  It returns true if the helper passed.
+//
+// The failure message includes the helper's output as go test -v would have
+// printed it.
+func AssertHelperPasses(t TT, f func(TT), msgAndArgs ...interface{}) bool {
+
+	tt := NewTestTester()
+	status := tt.RunHelper(f)
+
+	if tt.Failed() {
+		return assert.Fail(t,
+			"Helper did not pass:\n"+helperReport(tt, status), msgAndArgs...)
+	}
+	return true
+}
+
+// helperReport describes how the helper run by tt ended, for the failure
+// messages of AssertHelperFails and AssertHelperPasses.
+func helperReport(tt *TestTester, status RunStatus) string {
+
+	lines := []string{"    status: " + status.String()}
+	if tt.Panic != nil {
+		lines = append(lines, fmt.Sprintf("     panic: %v", tt.Panic))
+	}
+	lines = append(lines, "    output:")
+	for _, line := range strings.SplitAfter(tt.Output(true), "\n") {
+		if line != "" {
+			lines = append(lines, "      "+strings.TrimSuffix(line, "\n"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
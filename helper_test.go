@@ -0,0 +1,117 @@
+// helper_test.go
+
+package testig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+// requirePositive is our helper under test.
+func requirePositive(t testig.TT, n int) {
+	if n < 1 {
+		t.Fatalf("not positive: %d", n)
+	}
+}
+
+func Test_AssertHelperFails_Success(t *testing.T) {
+
+	assert := assert.New(t)
+
+	ok := testig.AssertHelperFails(t, func(t testig.TT) {
+		requirePositive(t, 0)
+	}, "^not positive: 0$")
+	assert.True(ok, "returns true")
+
+	ok = testig.AssertHelperFails(t, func(t testig.TT) {
+		panic("boom")
+	}, "boom")
+	assert.True(ok, "returns true for panic")
+
+	ok = testig.AssertHelperFails(t, func(t testig.TT) { t.Fail() }, "")
+	assert.True(ok, "returns true for silent failure with empty pattern")
+
+}
+
+func Test_AssertHelperFails_NoFailure(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tester := testig.NewTestTester()
+	ok := testig.AssertHelperFails(tester, func(t testig.TT) {
+		t.Log("all good")
+		requirePositive(t, 1)
+	}, "not positive", "my %s", "test")
+	assert.False(ok, "returns false")
+	assert.True(tester.Failed(), "tester Failed")
+	assert.False(tester.Stopped, "tester not Stopped")
+	if assert.Equal(1, len(tester.Logs), "one thing logged") {
+		assert.Regexp("Helper did not fail:", tester.Logs[0])
+		assert.Regexp("status: returned", tester.Logs[0])
+		assert.Regexp("=== RUN   TestTester", tester.Logs[0])
+		assert.Regexp(`helper_test.go:\d+: all good`, tester.Logs[0])
+		assert.Regexp("--- PASS: TestTester", tester.Logs[0])
+		assert.Regexp("my test", tester.Logs[0])
+	}
+
+}
+
+func Test_AssertHelperFails_WrongFailure(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tester := testig.NewTestTester()
+	ok := testig.AssertHelperFails(tester, func(t testig.TT) {
+		requirePositive(t, -1)
+	}, "not positive: 0")
+	assert.False(ok, "returns false")
+	if assert.Equal(1, len(tester.Logs), "one thing logged") {
+		assert.Regexp("Helper failure not as expected:", tester.Logs[0])
+		assert.Regexp("expected: Regexp /not positive: 0/", tester.Logs[0])
+		assert.Regexp("status: stopped", tester.Logs[0])
+		assert.Regexp("not positive: -1", tester.Logs[0])
+	}
+
+}
+
+func Test_AssertHelperFails_BadRegexp(t *testing.T) {
+
+	testig.AssertPanicsRegexp(t, func() {
+		testig.AssertHelperFails(t, func(t testig.TT) {}, "(")
+	}, "regexp")
+
+}
+
+func Test_AssertHelperPasses_Success(t *testing.T) {
+
+	ok := testig.AssertHelperPasses(t, func(t testig.TT) {
+		requirePositive(t, 1)
+	})
+	assert.True(t, ok, "returns true")
+
+	ok = testig.AssertHelperPasses(t, func(t testig.TT) { t.Skip("later") })
+	assert.True(t, ok, "returns true for skip")
+
+}
+
+func Test_AssertHelperPasses_Failure(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tester := testig.NewTestTester()
+	ok := testig.AssertHelperPasses(tester, func(t testig.TT) {
+		panic("boom")
+	})
+	assert.False(ok, "returns false")
+	assert.True(tester.Failed(), "tester Failed")
+	if assert.Equal(1, len(tester.Logs), "one thing logged") {
+		assert.Regexp("Helper did not pass:", tester.Logs[0])
+		assert.Regexp("status: panicked", tester.Logs[0])
+		assert.Regexp("panic: boom", tester.Logs[0])
+		assert.Regexp("--- FAIL: TestTester", tester.Logs[0])
+	}
+
+}
@@ -0,0 +1,170 @@
+// input.go -- scripted standard input for output recorders.
+
+package testig
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InputScript is a scripted standard input for an OutputRecorder, for code
+// that reads from an io.Reader which can be overridden in tests:
+//
+//	var stdin io.Reader = os.Stdin // in the code under test
+//	...
+//	r := NewOutputRecorder()
+//	stdin = r.Stdin
+//	r.Stdin.Feed("first\n")
+//	r.Stdin.Expect("Continue? ", "y\n")
+//	r.Stdin.FeedEOF()
+//
+// The script is made of steps, which Read works through in the order they
+// were added.  Once it has run out, Read returns io.EOF.
+type InputScript struct {
+	PromptWait time.Duration // how long Read waits for a prompt; zero for none
+
+	r        *OutputRecorder
+	mu       sync.Mutex
+	steps    []inputStep
+	consumed []byte
+	seen     int // of the recorder's stdout, already matched to prompts
+}
+
+// inputStep is one step of an InputScript.
+type inputStep struct {
+	prompt string // awaited before anything else, if set
+	data   []byte
+	eof    bool
+	err    error
+}
+
+// Feed adds s to the script, to be read as soon as the steps before it have
+// been.
+func (in *InputScript) Feed(s string) {
+	in.add(inputStep{data: []byte(s)})
+}
+
+// Expect adds reply to the script, to be read only once prompt has been
+// written to the recorder's Stdout after anything matched by the prompts of
+// earlier steps.  If the prompt has not been written when Read gets to the
+// step, it waits up to PromptWait for it, and failing that returns an error.
+// Thus code that prints its prompts from the reading goroutine, as most
+// does, needs no PromptWait.
+func (in *InputScript) Expect(prompt, reply string) {
+	in.add(inputStep{prompt: prompt, data: []byte(reply)})
+}
+
+// FeedEOF adds an end of input to the script: Read returns io.EOF once on
+// reaching it, as when a terminal user types an end-of-file character, and
+// then goes on to any later steps.
+func (in *InputScript) FeedEOF() {
+	in.add(inputStep{eof: true})
+}
+
+// FeedError adds a read error to the script: Read returns err once on
+// reaching it, and then goes on to any later steps.
+func (in *InputScript) FeedError(err error) {
+	in.add(inputStep{err: err})
+}
+
+// add adds a step to the script.
+func (in *InputScript) add(step inputStep) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.steps = append(in.steps, step)
+}
+
+// Read implements io.Reader, reading from the script.  While it waits for a
+// prompt the script is unlocked, so steps can be added meanwhile.
+func (in *InputScript) Read(p []byte) (int, error) {
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	for len(in.steps) > 0 {
+		if err := in.awaitPrompt(); err != nil {
+			return 0, err
+		}
+		if len(in.steps) == 0 {
+			break
+		}
+		step := &in.steps[0]
+		switch {
+		case step.err != nil:
+			in.steps = in.steps[1:]
+			return 0, step.err
+		case step.eof:
+			in.steps = in.steps[1:]
+			return 0, io.EOF
+		case len(step.data) == 0:
+			in.steps = in.steps[1:]
+			continue
+		}
+		n := copy(p, step.data)
+		in.consumed = append(in.consumed, step.data[:n]...)
+		step.data = step.data[n:]
+		if len(step.data) == 0 {
+			in.steps = in.steps[1:]
+		}
+		return n, nil
+	}
+	return 0, io.EOF
+}
+
+// awaitPrompt waits for the prompt of the first step, if it has one, to be
+// written to the recorder's stdout past the part already matched, waiting up
+// to PromptWait, and then clears it.  The caller must hold the lock, which is
+// released while waiting; since the steps may change meanwhile, the first
+// step is checked again each time.
+func (in *InputScript) awaitPrompt() error {
+
+	var deadline <-chan time.Time
+	if in.PromptWait > 0 {
+		timer := time.NewTimer(in.PromptWait)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	for len(in.steps) > 0 && in.steps[0].prompt != "" {
+		prompt := in.steps[0].prompt
+		wrote := in.r.nextWrite()
+		out := in.r.StdoutString()
+		if i := strings.Index(out[in.seen:], prompt); i >= 0 {
+			in.seen += i + len(prompt)
+			in.steps[0].prompt = ""
+			return nil
+		}
+		if deadline == nil {
+			return fmt.Errorf("testig: prompt %q not written", prompt)
+		}
+		in.mu.Unlock()
+		select {
+		case <-wrote:
+			in.mu.Lock()
+		case <-deadline:
+			in.mu.Lock()
+			return fmt.Errorf("testig: prompt %q not written in %v",
+				prompt, in.PromptWait)
+		}
+	}
+	return nil
+}
+
+// Consumed returns everything read from the script so far.
+func (in *InputScript) Consumed() string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return string(in.consumed)
+}
+
+// Done reports whether every step of the script has been read.
+func (in *InputScript) Done() bool {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.steps) == 0
+}
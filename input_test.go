@@ -0,0 +1,167 @@
+// input_test.go
+
+package testig_test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+func Test_InputScript_Feed(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	r.Stdin.Feed("one\ntwo\n")
+	r.Stdin.Feed("three\n")
+	assert.False(r.Stdin.Done(), "not Done")
+
+	lines := []string{}
+	scanner := bufio.NewScanner(r.Stdin)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	assert.NoError(scanner.Err(), "ended with EOF")
+	assert.Equal([]string{"one", "two", "three"}, lines, "all read")
+	assert.Equal("one\ntwo\nthree\n", r.Stdin.Consumed(), "all consumed")
+	assert.True(r.Stdin.Done(), "Done")
+
+}
+
+// askName is a tiny interactive program for the tests.
+func askName(r *testig.OutputRecorder) {
+	in := bufio.NewReader(r.Stdin)
+	for {
+		fmt.Fprint(r.Stdout, "Name? ")
+		name, err := in.ReadString('\n')
+		if err != nil {
+			fmt.Fprintln(r.Stderr, "error:", err)
+			return
+		}
+		fmt.Fprintf(r.Stdout, "Hello, %s", name)
+	}
+}
+
+func Test_InputScript_Expect(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	r.Stdin.Expect("Name? ", "Alice\n")
+	r.Stdin.Expect("Name? ", "Bob\n")
+	r.Stdin.FeedEOF()
+	r.Stdin.Feed("never read")
+	askName(r)
+
+	assert.Equal("Name? Hello, Alice\nName? Hello, Bob\nName? ",
+		r.StdoutString(), "prompted and replied")
+	assert.Equal("error: EOF\n", r.StderrString(), "EOF read")
+	assert.Equal("Alice\nBob\n", r.Stdin.Consumed(), "replies consumed")
+	assert.False(r.Stdin.Done(), "rest not read")
+
+}
+
+func Test_InputScript_Expect_NotWritten(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	r.Stdin.Expect("Password: ", "secret\n")
+	askName(r)
+	assert.Equal("error: testig: prompt \"Password: \" not written\n",
+		r.StderrString(), "no wait")
+
+	r = testig.NewOutputRecorder()
+	r.Stdin.PromptWait = time.Millisecond
+	r.Stdin.Expect("Password: ", "secret\n")
+	askName(r)
+	assert.Equal("error: testig: prompt \"Password: \" not written in 1ms\n",
+		r.StderrString(), "waited")
+	assert.Equal("", r.Stdin.Consumed(), "nothing consumed")
+
+}
+
+func Test_InputScript_Expect_Wait(t *testing.T) {
+
+	r := testig.NewOutputRecorder()
+	r.Stdin.PromptWait = time.Minute
+	r.Stdin.Expect("ready", "go")
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprint(r.Stdout, "getting ")
+		fmt.Fprint(r.Stdout, "ready")
+	}()
+	b, err := io.ReadAll(r.Stdin)
+	assert.NoError(t, err, "no error")
+	assert.Equal(t, "go", string(b), "reply read once prompt written")
+
+}
+
+func Test_InputScript_Expect_WaitUnlocked(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	r.Stdin.PromptWait = time.Minute
+	r.Stdin.Expect("ready", "go\n")
+	read := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r.Stdin)
+		read <- string(b)
+	}()
+
+	time.Sleep(10 * time.Millisecond) // let Read start waiting
+	fed := make(chan bool)
+	go func() {
+		r.Stdin.Feed("more\n")
+		close(fed)
+	}()
+	select {
+	case <-fed:
+	case <-time.After(time.Second):
+		t.Fatal("Feed blocked by waiting Read")
+	}
+	assert.Equal("", r.Stdin.Consumed(), "nothing consumed while waiting")
+	assert.False(r.Stdin.Done(), "not done while waiting")
+
+	fmt.Fprint(r.Stdout, "ready")
+	assert.Equal("go\nmore\n", <-read, "reply then fed input read")
+
+}
+
+func Test_InputScript_FeedError(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	boom := errors.New("boom")
+	r.Stdin.Feed("ab")
+	r.Stdin.FeedError(boom)
+	r.Stdin.FeedEOF()
+	r.Stdin.Feed("c")
+
+	buf := make([]byte, 1)
+	n, err := r.Stdin.Read(buf)
+	assert.Equal(1, n, "partial read")
+	assert.NoError(err, "no error yet")
+	r.Stdin.Read(buf)
+	_, err = r.Stdin.Read(buf)
+	assert.Equal(boom, err, "error read")
+	_, err = r.Stdin.Read(buf)
+	assert.Equal(io.EOF, err, "EOF read")
+	n, err = r.Stdin.Read(buf)
+	assert.Equal(1, n, "read after EOF")
+	assert.NoError(err, "no error after EOF")
+	_, err = r.Stdin.Read(buf)
+	assert.Equal(io.EOF, err, "EOF at end")
+	assert.Equal("abc", r.Stdin.Consumed(), "consumed")
+
+}
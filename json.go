@@ -0,0 +1,369 @@
+// json.go -- go test -json (test2json) event streams.
+
+package testig
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TestEvent mirrors the events written by go test -json, as documented for
+// cmd/test2json.
+type TestEvent struct {
+	Time       *time.Time `json:",omitempty"`
+	Action     string
+	Package    string   `json:",omitempty"`
+	Test       string   `json:",omitempty"`
+	Elapsed    *float64 `json:",omitempty"` // seconds
+	Output     string   `json:",omitempty"`
+	OutputType string   `json:",omitempty"`
+}
+
+// step is a single entry in a TestTester's timeline: an Event, a subtest,
+// or the point at which the test paused because it called Parallel.
+type step struct {
+	event *Event
+	sub   *TestTester
+	pause bool
+}
+
+// timeline returns the Events and subtests of tt in the order in which they
+// happened, with a pause step if it called Parallel.
+func (tt *TestTester) timeline() []step {
+
+	tt.mu.RLock()
+	events := make([]Event, len(tt.Events))
+	copy(events, tt.Events)
+	subs := make([]*TestTester, len(tt.children))
+	copy(subs, tt.children)
+	parallel, parallelIdx := tt.parallel, tt.parallelIdx
+	tt.mu.RUnlock()
+
+	steps := []step{}
+	addUpTo := func(i int) {
+		if parallel && parallelIdx <= i {
+			steps = append(steps, step{pause: true})
+			parallel = false
+		}
+		for len(subs) > 0 && subs[0].eventIdx <= i {
+			steps = append(steps, step{sub: subs[0]})
+			subs = subs[1:]
+		}
+	}
+	for i := range events {
+		addUpTo(i)
+		steps = append(steps, step{event: &events[i]})
+	}
+	addUpTo(len(events))
+	for _, sub := range subs {
+		steps = append(steps, step{sub: sub})
+	}
+	return steps
+}
+
+// streamKind identifies a point in the output of a test stream.
+type streamKind int
+
+const (
+	streamRun streamKind = iota
+	streamEvent
+	streamPause
+	streamCont
+	streamDone
+)
+
+// stream calls visit for each point in the output of tt and its subtests, in
+// the order go test would print them: parallel subtests are paused and only
+// continued once their parent's other steps are done.
+func (tt *TestTester) stream(visit func(streamKind, *TestTester, *Event)) {
+	visit(streamRun, tt, nil)
+	if cont := tt.streamSteps(tt.timeline(), nil, visit); cont != nil {
+		cont()
+	}
+}
+
+// streamSteps streams steps for tt as described for stream, continuing any
+// paused subtests at the end.  If tt pauses it returns a function that
+// continues the stream from there, and otherwise nil.
+func (tt *TestTester) streamSteps(steps []step, paused []func(), visit func(streamKind, *TestTester, *Event)) func() {
+
+	for i, s := range steps {
+		switch {
+		case s.pause:
+			visit(streamPause, tt, nil)
+			rest := steps[i+1:]
+			return func() {
+				visit(streamCont, tt, nil)
+				tt.streamSteps(rest, paused, visit)
+			}
+		case s.sub != nil:
+			visit(streamRun, s.sub, nil)
+			if cont := s.sub.streamSteps(s.sub.timeline(), nil, visit); cont != nil {
+				paused = append(paused, cont)
+			}
+		default:
+			visit(streamEvent, tt, s.event)
+		}
+	}
+	for _, cont := range paused {
+		cont()
+	}
+	visit(streamDone, tt, nil)
+	return nil
+}
+
+// result returns the result of tt as shown by go test: PASS, FAIL or SKIP.
+func (tt *TestTester) result() string {
+	switch {
+	case tt.Failed():
+		return "FAIL"
+	case tt.Skipped():
+		return "SKIP"
+	default:
+		return "PASS"
+	}
+}
+
+// elapsed returns the elapsed time of tt's run, rounded to hundredths of a
+// second as in go test output.
+func (tt *TestTester) elapsed() float64 {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+	if tt.finished.Before(tt.started) {
+		return 0
+	}
+	return math.Round(tt.finished.Sub(tt.started).Seconds()*100) / 100
+}
+
+// decorate returns the output lines for ev as testing.T would print them,
+// each ending in a newline: the first prefixed with the file and line, and
+// the rest indented.  Unlogged events have no output.
+func decorate(ev Event) []string {
+
+	if !ev.Logged {
+		return nil
+	}
+	lines := strings.Split(ev.Message, "\n")
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if i == 0 {
+			if ev.File == "" {
+				out[i] = "    " + line + "\n"
+			} else {
+				out[i] = fmt.Sprintf("    %s:%d: %s\n",
+					filepath.Base(ev.File), ev.Line, line)
+			}
+		} else {
+			out[i] = "        " + line + "\n"
+		}
+	}
+	return out
+}
+
+// WriteJSON writes the run of tt and all its subtests to w as a stream of
+// go test -json events, with pkg as the Package.  Events are written in the
+// same order and format as go test writes them, but without the surrounding
+// package-level events.
+func (tt *TestTester) WriteJSON(w io.Writer, pkg string) error {
+	enc := json.NewEncoder(w)
+	for _, ev := range tt.TestEvents(pkg) {
+		if err := enc.Encode(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestEvents returns the run of tt and all its subtests as go test -json
+// events, as written by WriteJSON.
+func (tt *TestTester) TestEvents(pkg string) []TestEvent {
+
+	events := []TestEvent{}
+	add := func(t *TestTester, when time.Time, action, output, outputType string) {
+		when = when.Round(0)
+		events = append(events, TestEvent{
+			Time:       &when,
+			Action:     action,
+			Package:    pkg,
+			Test:       t.name,
+			Output:     output,
+			OutputType: outputType,
+		})
+	}
+
+	tt.stream(func(kind streamKind, t *TestTester, ev *Event) {
+		t.mu.RLock()
+		started, finished := t.started, t.finished
+		pauseTime, contTime := t.pauseTime, t.contTime
+		t.mu.RUnlock()
+
+		switch kind {
+		case streamRun:
+			if !contTime.IsZero() {
+				started = pauseTime.Add(started.Sub(contTime)) // before reset
+			}
+			add(t, started, "run", "", "")
+			add(t, started, "output", "=== RUN   "+t.name+"\n", "frame")
+		case streamEvent:
+			outputType := ""
+			if ev.Kind == EventError || ev.Kind == EventFatal {
+				outputType = "error"
+			}
+			for _, line := range decorate(*ev) {
+				add(t, ev.Time, "output", line, outputType)
+				if outputType == "error" {
+					outputType = "error-continue"
+				}
+			}
+		case streamPause:
+			add(t, pauseTime, "output", "=== PAUSE "+t.name+"\n", "frame")
+			add(t, pauseTime, "pause", "", "")
+		case streamCont:
+			add(t, contTime, "cont", "", "")
+			add(t, contTime, "output", "=== CONT  "+t.name+"\n", "frame")
+		case streamDone:
+			elapsed := t.elapsed()
+			add(t, finished, "output", t.footer(), "frame")
+			add(t, finished, strings.ToLower(t.result()), "", "")
+			events[len(events)-1].Elapsed = &elapsed
+		}
+	})
+
+	return events
+}
+
+// logLineRegexp matches the first output line of a log event.
+var logLineRegexp = regexp.MustCompile(`^    (\S+?):(\d+): (.*)$`)
+
+// ParseJSON reads a stream of go test -json events from r and returns the
+// tests it describes as a tree of TestTesters, one per top-level test in the
+// order they were run.  Package-level events are ignored.
+//
+// The logged output of each test becomes its Events and Logs, with Errors
+// and Fatals indistinguishable (both are EventError) and a Skip message
+// recognized as such only if it was the last thing logged.  Stopped is only
+// set for skipped tests, as FailNow leaves no trace in the output.
+func ParseJSON(r io.Reader) ([]*TestTester, error) {
+
+	roots := []*TestTester{}
+	byName := map[string]*TestTester{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var te TestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &te); err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		if te.Test == "" {
+			continue
+		}
+		var when time.Time
+		if te.Time != nil {
+			when = *te.Time
+		}
+
+		tt := byName[te.Test]
+		if tt == nil {
+			tt = &TestTester{Logs: []string{}, name: te.Test, started: when}
+			byName[te.Test] = tt
+			if parent := findParent(byName, te.Test); parent != nil {
+				tt.parent = parent
+				tt.eventIdx = len(parent.Events)
+				parent.children = append(parent.children, tt)
+			} else {
+				roots = append(roots, tt)
+			}
+		}
+
+		switch te.Action {
+		case "pause":
+			tt.parallel = true
+			tt.parallelIdx = len(tt.Events)
+			tt.pauseTime = when
+		case "cont":
+			tt.contTime = when
+		case "output":
+			parseOutput(tt, te, when)
+		case "pass", "fail", "skip":
+			tt.finished = when
+			if te.Elapsed != nil {
+				tt.started = when.Add(
+					-time.Duration(*te.Elapsed * float64(time.Second)))
+			}
+			if te.Action == "fail" {
+				tt.failed = true
+			}
+			if te.Action == "skip" {
+				tt.skipped = true
+				tt.Stopped = true
+				if n := len(tt.Events); n > 0 &&
+					tt.Events[n-1].Kind == EventLog &&
+					tt.Events[n-1].File != "" {
+					tt.Events[n-1].Kind = EventSkip
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
+
+// findParent returns the TestTester in byName that is the closest parent of
+// the test called name, or nil if there is none.
+func findParent(byName map[string]*TestTester, name string) *TestTester {
+	for {
+		i := strings.LastIndex(name, "/")
+		if i < 0 {
+			return nil
+		}
+		name = name[:i]
+		if parent := byName[name]; parent != nil {
+			return parent
+		}
+	}
+}
+
+// parseOutput adds the output in te to tt as an Event, or to its last Event
+// if it is a continuation line.  Framing lines are ignored.
+func parseOutput(tt *TestTester, te TestEvent, when time.Time) {
+
+	line := strings.TrimSuffix(te.Output, "\n")
+	if te.OutputType == "frame" ||
+		strings.HasPrefix(line, "=== ") || strings.HasPrefix(line, "--- ") {
+		return
+	}
+
+	n := len(tt.Events)
+	if strings.HasPrefix(line, "        ") && n > 0 && tt.Events[n-1].Logged {
+		last := &tt.Events[n-1]
+		last.Message += "\n" + line[8:]
+		tt.Logs[len(tt.Logs)-1] = last.Message
+		return
+	}
+
+	ev := Event{Kind: EventLog, Message: line, Logged: true, Time: when}
+	if m := logLineRegexp.FindStringSubmatch(line); m != nil {
+		ev.File = m[1]
+		ev.Line, _ = strconv.Atoi(m[2])
+		ev.Message = m[3]
+	}
+	if te.OutputType == "error" {
+		ev.Kind = EventError
+	}
+	tt.Events = append(tt.Events, ev)
+	tt.Logs = append(tt.Logs, ev.Message)
+}
@@ -0,0 +1,220 @@
+// json_test.go
+
+package testig_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+// goTestJSON is the (trimmed) output of go test -json for:
+//
+//	func TestA(t *testing.T) {
+//		t.Log("hello\nworld")
+//		t.Run("sub one", func(t *testing.T) {
+//			t.Error("bad")
+//			t.Run("deep", func(t *testing.T) { t.Log("x") })
+//		})
+//		t.Run("skip", func(t *testing.T) { t.Skip("later") })
+//	}
+//
+//	func TestB(t *testing.T) { t.Log("fine") }
+const goTestJSON = `{"Time":"2026-10-18T07:07:59.311006516Z","Action":"start","Package":"example.com/j"}
+{"Time":"2026-10-18T07:07:59.313713Z","Action":"run","Package":"example.com/j","Test":"TestA"}
+{"Time":"2026-10-18T07:07:59.313774879Z","Action":"output","Package":"example.com/j","Test":"TestA","Output":"=== RUN   TestA\n","OutputType":"frame"}
+{"Time":"2026-10-18T07:07:59.313888771Z","Action":"output","Package":"example.com/j","Test":"TestA","Output":"    j_test.go:6: hello\n"}
+{"Time":"2026-10-18T07:07:59.313895603Z","Action":"output","Package":"example.com/j","Test":"TestA","Output":"        world\n"}
+{"Time":"2026-10-18T07:07:59.313900964Z","Action":"run","Package":"example.com/j","Test":"TestA/sub_one"}
+{"Time":"2026-10-18T07:07:59.313905026Z","Action":"output","Package":"example.com/j","Test":"TestA/sub_one","Output":"=== RUN   TestA/sub_one\n","OutputType":"frame"}
+{"Time":"2026-10-18T07:07:59.313909919Z","Action":"output","Package":"example.com/j","Test":"TestA/sub_one","Output":"    j_test.go:8: bad\n","OutputType":"error"}
+{"Time":"2026-10-18T07:07:59.313930765Z","Action":"run","Package":"example.com/j","Test":"TestA/sub_one/deep"}
+{"Time":"2026-10-18T07:07:59.313934283Z","Action":"output","Package":"example.com/j","Test":"TestA/sub_one/deep","Output":"=== RUN   TestA/sub_one/deep\n","OutputType":"frame"}
+{"Time":"2026-10-18T07:07:59.314114144Z","Action":"output","Package":"example.com/j","Test":"TestA/sub_one/deep","Output":"    j_test.go:9: x\n"}
+{"Time":"2026-10-18T07:07:59.314124075Z","Action":"output","Package":"example.com/j","Test":"TestA/sub_one/deep","Output":"--- PASS: TestA/sub_one/deep (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-18T07:07:59.314132392Z","Action":"pass","Package":"example.com/j","Test":"TestA/sub_one/deep","Elapsed":0}
+{"Time":"2026-10-18T07:07:59.314144432Z","Action":"output","Package":"example.com/j","Test":"TestA/sub_one","Output":"--- FAIL: TestA/sub_one (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-18T07:07:59.314149039Z","Action":"fail","Package":"example.com/j","Test":"TestA/sub_one","Elapsed":0}
+{"Time":"2026-10-18T07:07:59.314152365Z","Action":"run","Package":"example.com/j","Test":"TestA/skip"}
+{"Time":"2026-10-18T07:07:59.314155479Z","Action":"output","Package":"example.com/j","Test":"TestA/skip","Output":"=== RUN   TestA/skip\n","OutputType":"frame"}
+{"Time":"2026-10-18T07:07:59.314160234Z","Action":"output","Package":"example.com/j","Test":"TestA/skip","Output":"    j_test.go:11: later\n"}
+{"Time":"2026-10-18T07:07:59.314164299Z","Action":"output","Package":"example.com/j","Test":"TestA/skip","Output":"--- SKIP: TestA/skip (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-18T07:07:59.314170614Z","Action":"skip","Package":"example.com/j","Test":"TestA/skip","Elapsed":0}
+{"Time":"2026-10-18T07:07:59.314174475Z","Action":"output","Package":"example.com/j","Test":"TestA","Output":"--- FAIL: TestA (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-18T07:07:59.314178572Z","Action":"fail","Package":"example.com/j","Test":"TestA","Elapsed":0}
+{"Time":"2026-10-18T07:07:59.314182738Z","Action":"run","Package":"example.com/j","Test":"TestB"}
+{"Time":"2026-10-18T07:07:59.314185807Z","Action":"output","Package":"example.com/j","Test":"TestB","Output":"=== RUN   TestB\n","OutputType":"frame"}
+{"Time":"2026-10-18T07:07:59.314190206Z","Action":"output","Package":"example.com/j","Test":"TestB","Output":"    j_test.go:14: fine\n"}
+{"Time":"2026-10-18T07:07:59.314195175Z","Action":"output","Package":"example.com/j","Test":"TestB","Output":"--- PASS: TestB (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-18T07:07:59.3141992Z","Action":"pass","Package":"example.com/j","Test":"TestB","Elapsed":0}
+{"Time":"2026-10-18T07:07:59.31420261Z","Action":"output","Package":"example.com/j","Output":"FAIL\n","OutputType":"frame"}
+{"Time":"2026-10-18T07:07:59.314542243Z","Action":"output","Package":"example.com/j","Output":"FAIL\texample.com/j\t0.003s\n","OutputType":"frame"}
+{"Time":"2026-10-18T07:07:59.314552835Z","Action":"fail","Package":"example.com/j","Elapsed":0.004}
+`
+
+// runTestA does with a TestTester what TestA does above.
+func runTestA() *testig.TestTester {
+	tt := testig.NewNamedTestTester("TestA")
+	tt.RunHelper(func(t testig.TT) {
+		t.Log("hello\nworld")
+		testig.RunSubtest(t, "sub one", func(t testig.TT) {
+			t.Error("bad")
+			testig.RunSubtest(t, "deep", func(t testig.TT) { t.Log("x") })
+		})
+		testig.RunSubtest(t, "skip", func(t testig.TT) { t.Skip("later") })
+	})
+	return tt
+}
+
+func Test_TestTester_TestEvents(t *testing.T) {
+
+	assert := assert.New(t)
+
+	// Compare to the real thing, minus times and line numbers.
+	exp := []string{}
+	for _, line := range strings.Split(goTestJSON, "\n") {
+		var ev testig.TestEvent
+		if line == "" || json.Unmarshal([]byte(line), &ev) != nil ||
+			ev.Test != "TestA" && !strings.HasPrefix(ev.Test, "TestA/") {
+			continue
+		}
+		exp = append(exp, ev.Action+" "+ev.Test+" "+ev.OutputType+" "+
+			stripLineNumbers(ev.Output))
+	}
+
+	got := []string{}
+	for _, ev := range runTestA().TestEvents("example.com/j") {
+		assert.Equal("example.com/j", ev.Package, "Package set")
+		assert.NotNil(ev.Time, "Time set")
+		if ev.Action == "pass" || ev.Action == "fail" || ev.Action == "skip" {
+			assert.NotNil(ev.Elapsed, "Elapsed set")
+		}
+		got = append(got, ev.Action+" "+ev.Test+" "+ev.OutputType+" "+
+			stripLineNumbers(ev.Output))
+	}
+	assert.Equal(exp, got, "same events as go test -json")
+
+}
+
+// stripLineNumbers makes log output comparable across files.
+func stripLineNumbers(s string) string {
+	if strings.HasPrefix(s, "    ") && strings.Contains(s, ".go:") {
+		i := strings.Index(s, ".go:")
+		j := strings.Index(s[i+4:], ":")
+		return "    FILE" + s[i+4+j:]
+	}
+	return s
+}
+
+func Test_TestTester_WriteJSON_RoundTrip(t *testing.T) {
+
+	assert := assert.New(t)
+
+	orig := runTestA()
+	buf := &bytes.Buffer{}
+	if err := orig.WriteJSON(buf, "example.com/j"); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(len(orig.TestEvents("x")), strings.Count(buf.String(), "\n"),
+		"one JSON line per event")
+
+	roots, err := testig.ParseJSON(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Equal(1, len(roots), "one root") {
+		return
+	}
+	origNames, parsedNames := []string{}, []string{}
+	orig.Walk(func(tt *testig.TestTester) {
+		origNames = append(origNames, tt.Name())
+	})
+	roots[0].Walk(func(tt *testig.TestTester) {
+		parsedNames = append(parsedNames, tt.Name())
+		testig.AssertOutcome(t, tt, testig.OutcomeOf(orig.Find(tt.Name())),
+			tt.Name())
+	})
+	assert.Equal(origNames, parsedNames, "same tree")
+
+}
+
+func Test_ParseJSON_GoTest(t *testing.T) {
+
+	assert := assert.New(t)
+
+	roots, err := testig.ParseJSON(strings.NewReader(goTestJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Equal(2, len(roots), "two roots") {
+		return
+	}
+	a, b := roots[0], roots[1]
+	assert.Equal("TestA", a.Name())
+	assert.Equal("TestB", b.Name())
+
+	testig.AssertOutcome(t, a, testig.Outcome{
+		Failed: true,
+		Logs:   []string{"hello\nworld"},
+	}, "TestA")
+	testig.AssertOutcome(t, a.Find("TestA/sub_one"), testig.Outcome{
+		Failed: true,
+		Logs:   []string{"bad"},
+	}, "TestA/sub_one")
+	testig.AssertOutcome(t, a.Find("TestA/sub_one/deep"), testig.Outcome{
+		Logs: []string{"x"},
+	}, "TestA/sub_one/deep")
+	testig.AssertOutcome(t, a.Find("TestA/skip"), testig.Outcome{
+		Skipped: true,
+		Stopped: true,
+		Logs:    []string{"later"},
+	}, "TestA/skip")
+	testig.AssertOutcome(t, b, testig.Outcome{
+		Logs: []string{"fine"},
+	}, "TestB")
+
+	ev := a.Events[0]
+	assert.Equal("j_test.go", ev.File, "File parsed")
+	assert.Equal(6, ev.Line, "Line parsed")
+	assert.Equal(testig.EventError, a.Find("TestA/sub_one").Events[0].Kind,
+		"error output parsed as EventError")
+	assert.Equal(testig.EventSkip, a.Find("TestA/skip").Events[0].Kind,
+		"skip message parsed as EventSkip")
+
+	// And back again.
+	got := []string{}
+	for _, ev := range a.TestEvents("example.com/j") {
+		got = append(got, ev.Output)
+	}
+	assert.Contains(got, "    j_test.go:6: hello\n", "file and line kept")
+	assert.Contains(got, "        world\n", "continuation kept")
+
+}
+
+func Test_ParseJSON_RawOutput(t *testing.T) {
+
+	input := `{"Action":"run","Test":"TestX"}
+{"Action":"output","Test":"TestX","Output":"printed directly\n"}
+{"Action":"pass","Test":"TestX"}
+`
+	roots, err := testig.ParseJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"printed directly"}, roots[0].Logs)
+	assert.Equal(t, "", roots[0].Events[0].File, "no File")
+
+}
+
+func Test_ParseJSON_Error(t *testing.T) {
+
+	_, err := testig.ParseJSON(strings.NewReader("{\"Action\":\"run\"}\nnope\n"))
+	assert.EqualError(t, err,
+		"line 2: invalid character 'o' in literal null (expecting 'u')")
+
+}
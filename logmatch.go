@@ -0,0 +1,289 @@
+// logmatch.go -- matching TestTester logs.
+
+package testig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// LineMatcher matches a single log message.
+type LineMatcher interface {
+	MatchLine(msg string) bool
+	String() string // describes the match, for diffs
+}
+
+type exactLine string
+
+func (s exactLine) MatchLine(msg string) bool { return msg == string(s) }
+func (s exactLine) String() string            { return fmt.Sprintf("%q", string(s)) }
+
+// Exactly returns a LineMatcher matching a log message equal to s.
+func Exactly(s string) LineMatcher {
+	return exactLine(s)
+}
+
+type regexpLine struct {
+	re *regexp.Regexp
+}
+
+func (r regexpLine) MatchLine(msg string) bool { return r.re.MatchString(msg) }
+func (r regexpLine) String() string            { return "/" + r.re.String() + "/" }
+
+// Regexp returns a LineMatcher matching a log message that matches the
+// regular expression pat, which must compile.
+func Regexp(pat string) LineMatcher {
+	return regexpLine{regexp.MustCompile(pat)}
+}
+
+type containsLine string
+
+func (s containsLine) MatchLine(msg string) bool {
+	return strings.Contains(msg, string(s))
+}
+func (s containsLine) String() string { return fmt.Sprintf("contains %q", string(s)) }
+
+// Contains returns a LineMatcher matching a log message that contains s.
+func Contains(s string) LineMatcher {
+	return containsLine(s)
+}
+
+type normalizedLine string
+
+func (s normalizedLine) MatchLine(msg string) bool {
+	return normalizeSpace(msg) == string(s)
+}
+func (s normalizedLine) String() string {
+	return fmt.Sprintf("normalized %q", string(s))
+}
+
+// Normalized returns a LineMatcher matching a log message equal to s after
+// normalizing the whitespace of both: leading and trailing whitespace is
+// removed, and any other run of whitespace, including newlines, becomes a
+// single space.
+func Normalized(s string) LineMatcher {
+	return normalizedLine(normalizeSpace(s))
+}
+
+// normalizeSpace normalizes the whitespace in s as described for Normalized.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// LogMatcher matches a TestTester's Logs as a whole.
+type LogMatcher interface {
+	// DiffLogs describes how logs fail to match, or returns an empty string
+	// if they do match.
+	DiffLogs(logs []string) string
+}
+
+// InOrder returns a LogMatcher requiring the logs to include, in order, a
+// message matched by each of lines.  Other messages may come before, after
+// or in between.
+func InOrder(lines ...LineMatcher) LogMatcher {
+	return inOrder(lines)
+}
+
+type inOrder []LineMatcher
+
+func (m inOrder) DiffLogs(logs []string) string {
+	found := make([]int, len(m))
+	next := 0
+	ok := true
+	for i, lm := range m {
+		found[i] = -1
+		for j := next; j < len(logs); j++ {
+			if lm.MatchLine(logs[j]) {
+				found[i] = j
+				next = j + 1
+				break
+			}
+		}
+		if found[i] < 0 {
+			ok = false
+		}
+	}
+	if ok {
+		return ""
+	}
+	return diffLogs("expected, in order:", m, found, logs)
+}
+
+// AnyOrder returns a LogMatcher requiring the logs to include, in any order,
+// a different message matched by each of lines.  Other messages may be
+// present too.
+func AnyOrder(lines ...LineMatcher) LogMatcher {
+	return anyOrder(lines)
+}
+
+type anyOrder []LineMatcher
+
+func (m anyOrder) DiffLogs(logs []string) string {
+
+	// Find the largest matching of lines to logs, by augmenting paths.
+	owner := make([]int, len(logs)) // matcher index for each log, or -1
+	for j := range owner {
+		owner[j] = -1
+	}
+	var assign func(i int, seen []bool) bool
+	assign = func(i int, seen []bool) bool {
+		for j := range logs {
+			if seen[j] || !m[i].MatchLine(logs[j]) {
+				continue
+			}
+			seen[j] = true
+			if owner[j] < 0 || assign(owner[j], seen) {
+				owner[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	ok := true
+	for i := range m {
+		if !assign(i, make([]bool, len(logs))) {
+			ok = false
+		}
+	}
+	if ok {
+		return ""
+	}
+
+	found := make([]int, len(m))
+	for i := range found {
+		found[i] = -1
+	}
+	for j, i := range owner {
+		if i >= 0 {
+			found[i] = j
+		}
+	}
+	return diffLogs("expected, in any order:", m, found, logs)
+}
+
+// Lines returns a LogMatcher requiring exactly one log message per line,
+// each matched by the corresponding one of lines.
+func Lines(lines ...LineMatcher) LogMatcher {
+	return allLines(lines)
+}
+
+type allLines []LineMatcher
+
+func (m allLines) DiffLogs(logs []string) string {
+	found := make([]int, len(m))
+	ok := len(m) == len(logs)
+	for i, lm := range m {
+		found[i] = -1
+		if i < len(logs) && lm.MatchLine(logs[i]) {
+			found[i] = i
+		} else {
+			ok = false
+		}
+	}
+	if ok {
+		return ""
+	}
+	header := fmt.Sprintf("expected, one per log (%d, actual %d):",
+		len(m), len(logs))
+	return diffLogs(header, m, found, logs)
+}
+
+// diffLogs describes a failed match of lines to logs, where found gives the
+// index of the log matched by each line or -1.
+func diffLogs(header string, lines []LineMatcher, found []int, logs []string) string {
+
+	out := []string{header}
+	for i, lm := range lines {
+		if found[i] < 0 {
+			out = append(out, fmt.Sprintf("  ! %s (not matched)", lm))
+		} else {
+			out = append(out, fmt.Sprintf("  = %s (log %d)", lm, found[i]))
+		}
+	}
+	out = append(out, "actual:")
+	if len(logs) == 0 {
+		out = append(out, "    (none)")
+	}
+	for j, msg := range logs {
+		out = append(out, fmt.Sprintf("    %d: %q", j, msg))
+	}
+	return strings.Join(out, "\n")
+}
+
+// TestifyMessages returns a LogMatcher that applies m to logs reduced by
+// StripTestify, so that failures reported by testify's assert package can be
+// matched on their messages alone.
+func TestifyMessages(m LogMatcher) LogMatcher {
+	return testifyMessages{m}
+}
+
+type testifyMessages struct {
+	m LogMatcher
+}
+
+func (t testifyMessages) DiffLogs(logs []string) string {
+	stripped := make([]string, len(logs))
+	for i, msg := range logs {
+		stripped[i] = StripTestify(msg)
+	}
+	return t.m.DiffLogs(stripped)
+}
+
+// testifyLabelRegexp matches a labeled line of testify's assert output,
+// capturing the label, if any, and the content.
+var testifyLabelRegexp = regexp.MustCompile(`^\t(?:([A-Z][A-Za-z ]*):)? *\t(.*)$`)
+
+// StripTestify reduces a failure message logged by testify's assert package
+// to its essentials: the Error Trace and Test sections are removed, and the
+// content of the Error and Messages sections is returned without labels or
+// indentation, one after the other.  For instance:
+//
+//	"\n\tError Trace:\tfoo_test.go:12\n\tError:      \tShould be true\n" +
+//	    "\tTest:       \tTestFoo\n\tMessages:   \tfoo is bar\n"
+//
+// becomes:
+//
+//	"Should be true\nfoo is bar"
+//
+// Messages in any other format are returned as they are.
+func StripTestify(msg string) string {
+
+	lines := strings.Split(strings.TrimSuffix(msg, "\n"), "\n")
+	if len(lines) < 2 || lines[0] != "" ||
+		!strings.HasPrefix(lines[1], "\tError Trace:") {
+		return msg
+	}
+
+	kept := []string{}
+	label := ""
+	for _, line := range lines[1:] {
+		m := testifyLabelRegexp.FindStringSubmatch(line)
+		if m == nil {
+			return msg
+		}
+		if m[1] != "" {
+			label = m[1]
+		}
+		if label == "Error" || label == "Messages" {
+			kept = append(kept, m[2])
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// AssertLogs fails with msgAndArgs unless the Logs of the TestTester tt are
+// matched by m, describing the mismatch if not.  It is safe to omit
+// msgAndArgs.  It returns true if the logs matched:
+//
+//	AssertLogs(t, tt, InOrder(Contains("starting"), Regexp(`^done in \d+`)))
+func AssertLogs(t TT, tt *TestTester, m LogMatcher, msgAndArgs ...interface{}) bool {
+
+	diff := m.DiffLogs(OutcomeOf(tt).Logs)
+	if diff == "" {
+		return true
+	}
+	return assert.Fail(t, "Logs not as expected:\n"+diff, msgAndArgs...)
+}
@@ -0,0 +1,176 @@
+// logmatch_test.go
+
+package testig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+func Test_LineMatchers(t *testing.T) {
+
+	assert := assert.New(t)
+
+	cases := []struct {
+		lm    testig.LineMatcher
+		str   string
+		match string
+		miss  string
+	}{
+		{testig.Exactly("foo"), `"foo"`, "foo", "foo "},
+		{testig.Regexp(`^f\w+$`), `/^f\w+$/`, "foo", "bar"},
+		{testig.Contains("oo"), `contains "oo"`, "a foo", "bar"},
+		{testig.Normalized(" a  b\n"), `normalized "a b"`, "a\n\tb ", "ab"},
+	}
+	for _, c := range cases {
+		assert.Equal(c.str, c.lm.String(), "String")
+		assert.True(c.lm.MatchLine(c.match), "%s matches %q", c.lm, c.match)
+		assert.False(c.lm.MatchLine(c.miss), "%s misses %q", c.lm, c.miss)
+	}
+
+}
+
+func Test_InOrder(t *testing.T) {
+
+	assert := assert.New(t)
+
+	logs := []string{"one", "two", "three"}
+	m := testig.InOrder(testig.Exactly("one"), testig.Contains("ree"))
+	assert.Equal("", m.DiffLogs(logs), "subsequence matched")
+
+	m = testig.InOrder(testig.Exactly("three"), testig.Exactly("two"))
+	assert.Equal(`expected, in order:
+  = "three" (log 2)
+  ! "two" (not matched)
+actual:
+    0: "one"
+    1: "two"
+    2: "three"`, m.DiffLogs(logs), "out of order")
+
+	assert.Equal(`expected, in order:
+  ! "x" (not matched)
+actual:
+    (none)`, testig.InOrder(testig.Exactly("x")).DiffLogs(nil), "no logs")
+
+}
+
+func Test_AnyOrder(t *testing.T) {
+
+	assert := assert.New(t)
+
+	logs := []string{"ab", "a", "c"}
+
+	// The first matcher would greedily take "ab" and leave nothing for the
+	// second.
+	m := testig.AnyOrder(testig.Contains("a"), testig.Contains("b"))
+	assert.Equal("", m.DiffLogs(logs), "best assignment found")
+
+	m = testig.AnyOrder(testig.Exactly("c"), testig.Contains("b"),
+		testig.Contains("b"))
+	assert.Equal(`expected, in any order:
+  = "c" (log 2)
+  = contains "b" (log 0)
+  ! contains "b" (not matched)
+actual:
+    0: "ab"
+    1: "a"
+    2: "c"`, m.DiffLogs(logs), "distinct logs required")
+
+}
+
+func Test_Lines(t *testing.T) {
+
+	assert := assert.New(t)
+
+	logs := []string{"one", "two"}
+	m := testig.Lines(testig.Regexp("^o"), testig.Regexp("^t"))
+	assert.Equal("", m.DiffLogs(logs), "one per log")
+
+	m = testig.Lines(testig.Regexp("^o"))
+	assert.Equal(`expected, one per log (1, actual 2):
+  = /^o/ (log 0)
+actual:
+    0: "one"
+    1: "two"`, m.DiffLogs(logs), "too many logs")
+
+}
+
+func Test_StripTestify(t *testing.T) {
+
+	inner := testig.NewNamedTestTester("TestFoo")
+	innerAssert := assert.New(inner)
+	innerAssert.True(false, "foo is %s", "bar")
+	innerAssert.Equal("a\nb", "a\nc")
+
+	assert := assert.New(t)
+
+	if !assert.Equal(2, len(inner.Logs), "two failures") {
+		return
+	}
+	assert.Equal("Should be true\nfoo is bar",
+		testig.StripTestify(inner.Logs[0]), "stripped")
+	stripped := testig.StripTestify(inner.Logs[1])
+	assert.Regexp(`^Not equal: \n`, stripped, "multi-line error kept")
+	assert.NotContains(stripped, "Error Trace", "no trace")
+	assert.NotContains(stripped, "TestFoo", "no test name")
+
+	assert.Equal("plain", testig.StripTestify("plain"), "plain unchanged")
+	odd := "\n\tError Trace:\tx\nnot testify"
+	assert.Equal(odd, testig.StripTestify(odd), "odd unchanged")
+
+}
+
+func Test_TestifyMessages(t *testing.T) {
+
+	inner := testig.NewTestTester()
+	assert.Fail(inner, "something broke", "in %s", "part two")
+	m := testig.TestifyMessages(
+		testig.Lines(testig.Exactly("something broke\nin part two")))
+	assert.Equal(t, "", m.DiffLogs(inner.Logs), "matched without trace")
+
+}
+
+func Test_AssertLogs(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	tt.Log("hello world")
+
+	ok := testig.AssertLogs(t, tt, testig.InOrder(testig.Contains("world")))
+	assert.True(ok, "returns true")
+
+	tester := testig.NewTestTester()
+	ok = testig.AssertLogs(tester, tt,
+		testig.AnyOrder(testig.Exactly("bye")), "my %s", "test")
+	assert.False(ok, "returns false")
+	if assert.Equal(1, len(tester.Logs), "one thing logged") {
+		msg := testig.StripTestify(tester.Logs[0])
+		assert.Contains(msg, "Logs not as expected:\nexpected, in any order:")
+		assert.Contains(msg, `! "bye" (not matched)`)
+		assert.Contains(msg, `0: "hello world"`)
+		assert.Contains(msg, "my test")
+	}
+
+}
+
+func Test_HelperCases_MatchLogs(t *testing.T) {
+
+	tt := testig.NewTestTester()
+	ok := testig.HelperCases(tt, checkEven, []testig.HelperCase[int]{
+		{Name: "good", In: 2,
+			MatchLogs: testig.InOrder(testig.Regexp(`\d`))},
+		{Name: "bad", In: 4,
+			MatchLogs: testig.InOrder(testig.Exactly("odd"))},
+	})
+	assert.False(t, ok, "returns false")
+	subs := tt.Subtests()
+	assert.False(t, subs[0].Failed(), "good matched")
+	if assert.Equal(t, 1, len(subs[1].Logs), "bad reported") {
+		assert.Contains(t, subs[1].Logs[0], "Logs:     not matched")
+	}
+
+}
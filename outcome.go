@@ -0,0 +1,109 @@
+// outcome.go -- expected outcomes for TestTesters.
+
+package testig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Outcome summarizes the state of a TestTester after a test function has been
+// run, so that it can be checked in one go with AssertOutcome:
+//
+//	AssertOutcome(t, tt, Outcome{
+//	    Failed:  true,
+//	    Stopped: true,
+//	    Logs:    []string{"uh-oh spaghettio!"},
+//	})
+//
+// For the purposes of comparison, nil and empty Logs are equivalent.
+type Outcome struct {
+	Failed   bool
+	Skipped  bool
+	Stopped  bool
+	Panicked bool
+	Logs     []string
+}
+
+// OutcomeOf returns the current Outcome of tt.
+func OutcomeOf(tt *TestTester) Outcome {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+	logs := make([]string, len(tt.Logs))
+	copy(logs, tt.Logs)
+	return Outcome{
+		Failed:   tt.failed,
+		Skipped:  tt.skipped,
+		Stopped:  tt.Stopped,
+		Panicked: tt.Panic != nil,
+		Logs:     logs,
+	}
+}
+
+// Diff describes the differences between the expected Outcome exp and the
+// actual Outcome act, one line per differing property; or returns an empty
+// string if there are none.
+func (exp Outcome) Diff(act Outcome) string {
+
+	lines := []string{}
+	flag := func(name string, e, a bool) {
+		if e != a {
+			lines = append(lines,
+				fmt.Sprintf("%-9s expected %t, actual %t", name+":", e, a))
+		}
+	}
+	flag("Failed", exp.Failed, act.Failed)
+	flag("Skipped", exp.Skipped, act.Skipped)
+	flag("Stopped", exp.Stopped, act.Stopped)
+	flag("Panicked", exp.Panicked, act.Panicked)
+
+	if !equalLogs(exp.Logs, act.Logs) {
+		lines = append(lines, fmt.Sprintf("%-9s expected %d, actual %d",
+			"Logs:", len(exp.Logs), len(act.Logs)))
+		lines = append(lines, "  expected:")
+		lines = append(lines, quoteLogs(exp.Logs)...)
+		lines = append(lines, "  actual:")
+		lines = append(lines, quoteLogs(act.Logs)...)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// equalLogs reports whether a and b are equal, treating nil as empty.
+func equalLogs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteLogs formats logs for Diff, one per line.
+func quoteLogs(logs []string) []string {
+	if len(logs) == 0 {
+		return []string{"    (none)"}
+	}
+	quoted := make([]string, len(logs))
+	for i, s := range logs {
+		quoted[i] = fmt.Sprintf("    %q", s)
+	}
+	return quoted
+}
+
+// AssertOutcome fails with msgAndArgs unless the Outcome of the TestTester tt
+// is exp, reporting all the differences in a single failure message.  It is
+// safe to omit msgAndArgs.  It returns true if the outcome was as expected.
+func AssertOutcome(t TT, tt *TestTester, exp Outcome, msgAndArgs ...interface{}) bool {
+
+	diff := exp.Diff(OutcomeOf(tt))
+	if diff == "" {
+		return true
+	}
+	return assert.Fail(t, "Outcome not as expected:\n"+diff, msgAndArgs...)
+}
@@ -0,0 +1,114 @@
+// outcome_test.go
+
+package testig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+func Test_OutcomeOf(t *testing.T) {
+
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {
+		t.Fatal("oops")
+	})
+	exp := testig.Outcome{
+		Failed:  true,
+		Stopped: true,
+		Logs:    []string{"oops"},
+	}
+	assert.Equal(t, exp, testig.OutcomeOf(tt), "outcome as expected")
+
+	tt = testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) { panic("boom") })
+	assert.True(t, testig.OutcomeOf(tt).Panicked, "Panicked")
+
+}
+
+func Test_Outcome_Diff_Equal(t *testing.T) {
+
+	exp := testig.Outcome{Failed: true}
+	act := testig.Outcome{Failed: true, Logs: []string{}}
+	assert.Equal(t, "", exp.Diff(act), "no diff, nil Logs equal empty")
+
+}
+
+func Test_Outcome_Diff(t *testing.T) {
+
+	exp := testig.Outcome{
+		Failed:  true,
+		Stopped: true,
+		Logs:    []string{"foo", "bar"},
+	}
+	act := testig.Outcome{
+		Skipped:  true,
+		Stopped:  true,
+		Panicked: true,
+		Logs:     []string{"foo", "baz"},
+	}
+	diff := `Failed:   expected true, actual false
+Skipped:  expected false, actual true
+Panicked: expected false, actual true
+Logs:     expected 2, actual 2
+  expected:
+    "foo"
+    "bar"
+  actual:
+    "foo"
+    "baz"`
+	assert.Equal(t, diff, exp.Diff(act), "diff as expected")
+
+	exp = testig.Outcome{Logs: []string{"foo"}}
+	act = testig.Outcome{}
+	diff = `Logs:     expected 1, actual 0
+  expected:
+    "foo"
+  actual:
+    (none)`
+	assert.Equal(t, diff, exp.Diff(act), "diff as expected for no logs")
+
+}
+
+func Test_AssertOutcome_Success(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	tt.Skip("later")
+
+	tester := testig.NewTestTester()
+	ok := testig.AssertOutcome(tester, tt, testig.Outcome{
+		Skipped: true,
+		Stopped: true,
+		Logs:    []string{"later"},
+	})
+	assert.True(ok, "returns true")
+	assert.False(tester.Failed(), "tester not Failed")
+	assert.Equal([]string{}, tester.Logs, "nothing logged")
+
+}
+
+func Test_AssertOutcome_Failure(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	tt.Error("oops")
+
+	tester := testig.NewTestTester()
+	ok := testig.AssertOutcome(tester, tt, testig.Outcome{}, "my %s", "test")
+	assert.False(ok, "returns false")
+	assert.True(tester.Failed(), "tester Failed")
+	assert.False(tester.Stopped, "tester not Stopped")
+	if assert.Equal(1, len(tester.Logs), "one thing logged") {
+		assert.Regexp("Outcome not as expected:", tester.Logs[0])
+		assert.Regexp("Failed:   expected false, actual true", tester.Logs[0])
+		assert.Regexp(`"oops"`, tester.Logs[0])
+		assert.Regexp("my test", tester.Logs[0])
+	}
+
+}
@@ -0,0 +1,211 @@
+// parallel.go -- parallel subtests a la testing.T.Parallel.
+
+package testig
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// parallelConflict is the panic message of testing.T for mixing Parallel
+// with Setenv or Chdir.
+const parallelConflict = `testing: test using t.Setenv, t.Chdir, or cryptotest.SetGlobalRandom can not use t.Parallel`
+
+// Parallel mirrors the same-named function in testing.T: it signals that the
+// subtest is to be run in parallel with (and only with) other parallel
+// subtests of the same parent.  Run returns as soon as Parallel is called,
+// and the subtest is paused until the parent's test function returns.  It
+// then runs concurrently with the parent's other parallel subtests, up to
+// the limit set by MaxParallel on the root of the tree, and the parent waits
+// for them all before running its Cleanup functions.
+//
+// As in testing, calling Parallel twice panics, as does calling it in a test
+// that has used Setenv or Chdir.  It has no effect on a TestTester that is
+// not a subtest, on a subtest whose parent is not running a function with
+// RunHelper (as when Run is called on a TestTester used directly), or on the
+// seed inputs of a FuzzTester.
+//
+// Parallel is not part of the TT2 interface because testing.B does not have
+// it; helpers can call it with the package-level Parallel function.
+func (tt *TestTester) Parallel() {
+
+	tt.mu.Lock()
+	if tt.parallel {
+		tt.mu.Unlock()
+		panic("testing: t.Parallel called multiple times")
+	}
+	if tt.denyParallel {
+		tt.mu.Unlock()
+		panic(parallelConflict)
+	}
+	parent := tt.parent
+	paused := tt.pause != nil
+	tt.mu.Unlock()
+	if parent == nil || !paused {
+		return
+	}
+
+	// Only a parent running its function will ever release the subtest.
+	parent.mu.Lock()
+	rs := parent.current
+	if rs == nil || len(parent.runs) == 0 {
+		parent.mu.Unlock()
+		return
+	}
+	rs.subs = append(rs.subs, tt)
+	parent.mu.Unlock()
+
+	tt.mu.Lock()
+	tt.parallel = true
+	tt.parallelIdx = len(tt.Events)
+	tt.pauseTime = time.Now()
+	ran := tt.pauseTime.Sub(tt.started)
+	tt.mu.Unlock()
+
+	tt.pause <- struct{}{} // release Run in the parent
+	<-rs.barrier           // wait for the parent's function to return
+	tt.parallelState().wait()
+
+	// As in testing, the time spent paused does not count.
+	tt.mu.Lock()
+	tt.contTime = time.Now()
+	tt.started = tt.contTime.Add(-ran)
+	tt.mu.Unlock()
+}
+
+// Parallel calls t.Parallel if t has such a method, as *testing.T and the
+// TestTester do, and otherwise does nothing.  It is the TT-friendly way for
+// a helper to call t.Parallel:
+//
+//	func ParallelHelper(t TT) {
+//	    Parallel(t)
+//	    ...
+//	}
+func Parallel(t TT) {
+	if p, ok := t.(interface{ Parallel() }); ok {
+		p.Parallel()
+	}
+}
+
+// checkParallel panics if tt or any of its parents is parallel, and
+// otherwise prevents tt from becoming parallel, as for Setenv and Chdir.
+func (tt *TestTester) checkParallel() {
+
+	for t := tt; t != nil; t = t.parent {
+		t.mu.RLock()
+		parallel := t.parallel
+		t.mu.RUnlock()
+		if parallel {
+			panic(parallelConflict)
+		}
+	}
+	tt.mu.Lock()
+	tt.denyParallel = true
+	tt.mu.Unlock()
+}
+
+// finishParallel is called when the function of the run rs of tt has
+// finished.  It releases any parallel subtests, waits for them to finish and
+// marks tt as failed if any of them failed.  It also manages tt's share of
+// the limit on running parallel tests, as testing does: a test gives up its
+// share while waiting for its parallel subtests, and a parallel test gives it
+// up for good when done.  It does nothing if the run was abandoned after a
+// timeout, leaving any parallel subtests waiting.
+func (tt *TestTester) finishParallel(rs *runState) {
+
+	tt.mu.Lock()
+	if rs.abandoned {
+		tt.mu.Unlock()
+		return
+	}
+	subs := rs.subs
+	rs.subs = nil
+	parallel := tt.parallel
+	tt.mu.Unlock()
+
+	if len(subs) == 0 {
+		if parallel {
+			tt.parallelState().release()
+		}
+		return
+	}
+
+	state := tt.parallelState()
+	state.release()
+	close(rs.barrier)
+	for _, sub := range subs {
+		<-sub.done
+		if sub.Failed() {
+			tt.mu.Lock()
+			if !rs.abandoned {
+				tt.failed = true
+			}
+			tt.mu.Unlock()
+		}
+	}
+	if !parallel {
+		state.wait()
+	}
+}
+
+// parallelState limits the number of parallel tests running at once in a
+// TestTester tree, in the same way as testing.
+type parallelState struct {
+	mu      sync.Mutex
+	max     int
+	running int
+	waiting int
+	start   chan struct{}
+}
+
+// parallelState returns the parallelState of the tree to which tt belongs,
+// creating it on the root if necessary.  The root's own run counts as one
+// running test.
+func (tt *TestTester) parallelState() *parallelState {
+
+	root := tt
+	for root.parent != nil {
+		root = root.parent
+	}
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	if root.pstate == nil {
+		max := root.MaxParallel
+		if max < 1 {
+			max = runtime.GOMAXPROCS(0)
+		}
+		root.pstate = &parallelState{
+			max:     max,
+			running: 1,
+			start:   make(chan struct{}),
+		}
+	}
+	return root.pstate
+}
+
+// wait waits until another test may run.
+func (s *parallelState) wait() {
+	s.mu.Lock()
+	if s.running < s.max {
+		s.running++
+		s.mu.Unlock()
+		return
+	}
+	s.waiting++
+	s.mu.Unlock()
+	<-s.start
+}
+
+// release makes way for another test to run.
+func (s *parallelState) release() {
+	s.mu.Lock()
+	if s.waiting == 0 {
+		s.running--
+		s.mu.Unlock()
+		return
+	}
+	s.waiting--
+	s.mu.Unlock()
+	s.start <- struct{}{}
+}
@@ -0,0 +1,262 @@
+// parallel_test.go
+
+package testig_test
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+// runTestP does with a TestTester what this does with go test -parallel 1:
+//
+//	func TestP(t *testing.T) {
+//		t.Log("start")
+//		t.Run("a", func(t *testing.T) {
+//			t.Log("a before")
+//			t.Parallel()
+//			t.Log("a after")
+//		})
+//		t.Run("b", func(t *testing.T) {
+//			t.Parallel()
+//			t.Error("b fails")
+//		})
+//		t.Run("c", func(t *testing.T) { t.Log("c seq") })
+//		t.Log("end")
+//	}
+func runTestP() *testig.TestTester {
+	tt := testig.NewNamedTestTester("TestP")
+	tt.MaxParallel = 1
+	tt.RunHelper(func(t testig.TT) {
+		t.Log("start")
+		testig.RunSubtest(t, "a", func(t testig.TT) {
+			t.Log("a before")
+			testig.Parallel(t)
+			t.Log("a after")
+		})
+		testig.RunSubtest(t, "b", func(t testig.TT) {
+			testig.Parallel(t)
+			t.Error("b fails")
+		})
+		testig.RunSubtest(t, "c", func(t testig.TT) { t.Log("c seq") })
+		t.Log("end")
+	})
+	return tt
+}
+
+func Test_TestTester_Parallel_Order(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := runTestP()
+	assert.True(tt.Failed(), "parallel failure propagated")
+	assert.Equal([]string{"start", "end"}, tt.Logs, "parent logs")
+
+	exp := `=== RUN   TestP
+    x_test.go:1: start
+=== RUN   TestP/a
+    x_test.go:1: a before
+=== PAUSE TestP/a
+=== RUN   TestP/b
+=== PAUSE TestP/b
+=== RUN   TestP/c
+    x_test.go:1: c seq
+=== NAME  TestP
+    x_test.go:1: end
+=== CONT  TestP/a
+    x_test.go:1: a after
+=== CONT  TestP/b
+    x_test.go:1: b fails
+--- FAIL: TestP (0.00s)
+    --- PASS: TestP/c (0.00s)
+    --- PASS: TestP/a (0.00s)
+    --- FAIL: TestP/b (0.00s)
+`
+	assert.Equal(exp, anyFileLine(tt.Output(true)), "verbose output")
+
+	exp = `--- FAIL: TestP (0.00s)
+    x_test.go:1: start
+    x_test.go:1: end
+    --- FAIL: TestP/b (0.00s)
+        x_test.go:1: b fails
+`
+	assert.Equal(exp, anyFileLine(tt.Output(false)), "plain output")
+
+	got := []string{}
+	for _, ev := range tt.TestEvents("x") {
+		if ev.Action != "output" {
+			got = append(got, ev.Action+" "+ev.Test)
+		}
+	}
+	assert.Equal([]string{
+		"run TestP",
+		"run TestP/a",
+		"pause TestP/a",
+		"run TestP/b",
+		"pause TestP/b",
+		"run TestP/c",
+		"pass TestP/c",
+		"cont TestP/a",
+		"pass TestP/a",
+		"cont TestP/b",
+		"fail TestP/b",
+		"fail TestP",
+	}, got, "json actions")
+
+}
+
+func Test_TestTester_Parallel_RoundTrip(t *testing.T) {
+
+	tt := runTestP()
+	buf := &strings.Builder{}
+	if err := tt.WriteJSON(buf, "x"); err != nil {
+		t.Fatal(err)
+	}
+	roots, err := testig.ParseJSON(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tt.Output(true), roots[0].Output(true), "same output")
+
+}
+
+func Test_TestTester_Parallel_Limit(t *testing.T) {
+
+	assert := assert.New(t)
+
+	for _, max := range []int{1, 3} {
+		var running, most int32
+		var mu sync.Mutex
+		tt := testig.NewTestTester()
+		tt.MaxParallel = max
+		cleanedUp := false
+		tt.RunHelper(func(t testig.TT) {
+			tt.Cleanup(func() {
+				cleanedUp = atomic.LoadInt32(&running) == 0
+			})
+			for i := 0; i < 6; i++ {
+				ok := testig.RunSubtest(t, "p", func(t testig.TT) {
+					testig.Parallel(t)
+					n := atomic.AddInt32(&running, 1)
+					mu.Lock()
+					if n > most {
+						most = n
+					}
+					mu.Unlock()
+					time.Sleep(10 * time.Millisecond)
+					atomic.AddInt32(&running, -1)
+				})
+				assert.True(ok, "Run returns true when paused")
+			}
+			assert.Equal(int32(0), atomic.LoadInt32(&running),
+				"nothing running before parent returns")
+		})
+		assert.Equal(int32(max), most, "at most MaxParallel running")
+		assert.True(cleanedUp, "cleanup after parallel subtests")
+		assert.Equal(6, len(tt.Subtests()), "all subtests run")
+	}
+
+}
+
+func Test_TestTester_Parallel_Nested(t *testing.T) {
+
+	tt := testig.NewTestTester()
+	tt.MaxParallel = 1
+	order := []string{}
+	var mu sync.Mutex
+	add := func(s string) {
+		mu.Lock()
+		order = append(order, s)
+		mu.Unlock()
+	}
+	tt.RunHelper(func(t testig.TT) {
+		testig.RunSubtest(t, "outer", func(t testig.TT) {
+			testig.Parallel(t)
+			testig.RunSubtest(t, "inner", func(t testig.TT) {
+				testig.Parallel(t)
+				add("inner")
+			})
+			add("outer")
+		})
+		add("parent")
+	})
+	assert.Equal(t, []string{"parent", "outer", "inner"}, order,
+		"run in order without deadlock")
+
+}
+
+func Test_TestTester_Parallel_Panics(t *testing.T) {
+
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {
+		testig.RunSubtest(t, "twice", func(t testig.TT) {
+			testig.Parallel(t)
+			testig.AssertPanicsWith(t, func() { testig.Parallel(t) },
+				"testing: t.Parallel called multiple times")
+		})
+		testig.RunSubtest(t, "setenv", func(t testig.TT) {
+			testig.Parallel(t)
+			testig.AssertPanicsWith(t,
+				func() { t.(testig.TT2).Setenv("TESTIG_PARALLEL", "x") },
+				"testing: test using t.Setenv, t.Chdir, or "+
+					"cryptotest.SetGlobalRandom can not use t.Parallel")
+		})
+		testig.RunSubtest(t, "chdir", func(t testig.TT) {
+			t.(testig.TT2).Chdir(".")
+			testig.AssertPanicsRegexp(t, func() { testig.Parallel(t) },
+				"can not use t.Parallel")
+		})
+	})
+	assert.False(t, tt.Failed(), "all panicked as expected")
+	assert.Equal(t, []string{}, tt.Logs, "nothing logged")
+
+}
+
+func Test_TestTester_Parallel_NoEffect(t *testing.T) {
+
+	assert := assert.New(t)
+
+	// Not a subtest:
+	tt := testig.NewTestTester()
+	status := tt.RunHelper(func(t testig.TT) {
+		testig.Parallel(t)
+		t.Log("continued")
+	})
+	assert.Equal(testig.RunReturned, status, "returned")
+	assert.Equal([]string{"continued"}, tt.Logs, "continued")
+	assert.NotContains(tt.Output(true), "PAUSE", "no pause")
+
+	// Parent used directly, not run by RunHelper:
+	tt = testig.NewTestTester()
+	ok := tt.Run("p", func(t testig.TT) {
+		testig.Parallel(t)
+		t.Error("failed")
+	})
+	assert.False(ok, "subtest ran at once")
+	assert.True(tt.Failed(), "parent failed")
+	assert.NotContains(tt.Output(true), "PAUSE", "no pause")
+	assert.NotContains(tt.Output(true), "CONT", "no cont")
+
+	// Fuzz seeds:
+	ft := testig.NewFuzzTester()
+	ft.RunFuzz(func(f testig.FF) {
+		f.Add(1)
+		f.Fuzz(func(t testig.TT, n int) {
+			testig.Parallel(t)
+			t.Log("continued")
+		})
+	})
+	assert.Equal(testig.RunReturned, ft.Inputs[0].Status, "seed returned")
+	assert.Equal([]string{"continued"}, ft.Inputs[0].Tester.Logs,
+		"seed continued")
+
+	// No such method:
+	testig.Parallel(struct{ testig.TT }{testig.NewTestTester()})
+
+}
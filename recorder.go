@@ -3,56 +3,275 @@
 package testig
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
+	"runtime"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // OutputRecorder allows recording and inspection of output to the normal
-// channels Stdout and Stderr, as well as capture of the exit code.
+// channels Stdout and Stderr, as well as capture of the exit code.  Input can
+// be scripted with Stdin.
+//
+// Every write to Stdout or Stderr is recorded as a WriteEvent, so the output
+// can be had by stream or combined in the order it was written.
 type OutputRecorder struct {
-	Stdout   *bufio.Writer
-	Stderr   *bufio.Writer
-	Exited   bool
-	ExitCode int
-	ExitTime time.Time
+	Stdin       *InputScript
+	Stdout      *OutputStream
+	Stderr      *OutputStream
+	Exited      bool
+	ExitCode    int
+	ExitTime    time.Time
+	DeferOnExit bool // for Run; see Exit
+	CaptureFDs  bool // for Capture; Linux only
 
-	outBuf *bytes.Buffer
-	errBuf *bytes.Buffer
+	mu      sync.Mutex
+	writes  []WriteEvent
+	wrote   chan struct{} // closed on the next write, if set
+	running bool
+	exit    chan struct{} // closed on Exit under Run
+	gid     int64         // of the goroutine started by Run
+	capture *capture      // while capturing
 }
 
 // NewOutputRecorder returns an initialied OutputRecorder ready for use.
 func NewOutputRecorder() *OutputRecorder {
 
-	var outBytes bytes.Buffer
-	var errBytes bytes.Buffer
+	r := &OutputRecorder{ExitCode: -1}
+	r.Stdin = &InputScript{r: r}
+	r.Stdout = &OutputStream{r: r, name: "stdout"}
+	r.Stderr = &OutputStream{r: r, name: "stderr"}
+	return r
+}
+
+// OutputStream is the writer for one of the output streams of an
+// OutputRecorder.  It is safe for concurrent use.
+type OutputStream struct {
+	r    *OutputRecorder
+	name string
+}
+
+// Write records a WriteEvent for p, unless it is empty.  It never fails.
+func (s *OutputStream) Write(p []byte) (int, error) {
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.r.writes = append(s.r.writes, WriteEvent{
+		Stream: s.name,
+		Data:   append([]byte{}, p...),
+		Time:   time.Now(),
+		Seq:    len(s.r.writes),
+	})
+	if s.r.wrote != nil {
+		close(s.r.wrote)
+		s.r.wrote = nil
+	}
+	return len(p), nil
+}
+
+// WriteString is like Write but takes a string.
+func (s *OutputStream) WriteString(str string) (int, error) {
+	return s.Write([]byte(str))
+}
+
+// WriteByte records a WriteEvent for the byte c.  It never fails.
+func (s *OutputStream) WriteByte(c byte) error {
+	_, err := s.Write([]byte{c})
+	return err
+}
+
+// WriteRune records a WriteEvent for the UTF-8 encoding of the rune r, and
+// returns the number of bytes written.  It never fails.
+func (s *OutputStream) WriteRune(r rune) (int, error) {
+	return s.Write(utf8.AppendRune(nil, r))
+}
 
-	return &OutputRecorder{
-		Stdout:   bufio.NewWriter(&outBytes),
-		Stderr:   bufio.NewWriter(&errBytes),
-		outBuf:   &outBytes,
-		errBuf:   &errBytes,
-		ExitCode: -1,
+// Flush does nothing, as nothing is buffered.  It is kept for code written
+// when the streams were bufio.Writers.
+func (s *OutputStream) Flush() error {
+	return nil
+}
+
+// WriteEvent is a single write to one of the streams of an OutputRecorder.
+type WriteEvent struct {
+	Stream string // "stdout" or "stderr"
+	Data   []byte
+	Time   time.Time
+	Seq    int // position among all writes to the recorder, from zero
+}
+
+// Writes returns all the writes to Stdout and Stderr so far, in the order
+// they were made.
+//
+// While capturing, writes are recorded as the output is read from the
+// capturing pipes, so they are in order within each stream but may be
+// somewhat out of order between the two.
+func (r *OutputRecorder) Writes() []WriteEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]WriteEvent{}, r.writes...)
+}
+
+// nextWrite returns a channel closed on the next write to Stdout or Stderr.
+func (r *OutputRecorder) nextWrite() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.wrote == nil {
+		r.wrote = make(chan struct{})
 	}
+	return r.wrote
 }
 
-// Exit is a function suitable for overriding os.Exit.  If Exit is called more
-// than once it panics: in order for exiting functions to be reasonably
-// testable, they must not assume their exit calls actually terminate the
-// program.
+// streamString returns everything written to the named stream, or to both if
+// name is empty.
+func (r *OutputRecorder) streamString(name string) string {
+
+	var b strings.Builder
+	for _, w := range r.Writes() {
+		if name == "" || w.Stream == name {
+			b.Write(w.Data)
+		}
+	}
+	return b.String()
+}
+
+// CombinedString returns a string of all written to both standard output and
+// standard error so far, interleaved in the order it was written, as a
+// terminal would show it.
+func (r *OutputRecorder) CombinedString() string {
+	return r.streamString("")
+}
+
+// Transcript returns a description of all the writes so far, one per line
+// in the order they were made, each tagged with its stream:
+//
+//	stderr: "warning: no config\n"
+//	stdout: "result: "
+//	stdout: "42\n"
+func (r *OutputRecorder) Transcript() string {
+
+	var b strings.Builder
+	for _, w := range r.Writes() {
+		fmt.Fprintf(&b, "%s: %q\n", w.Stream, w.Data)
+	}
+	return b.String()
+}
+
+// Exit is a function suitable for overriding os.Exit.  It records the exit
+// code and, if called while a function is being run by Run, stops the
+// calling goroutine:
+//
+//	var osExit = os.Exit // in the code under test
+//	...
+//	r := NewOutputRecorder()
+//	osExit = r.Exit
+//	r.Run(func() { DoSomethingThatExits() })
+//
+// As with a real os.Exit, deferred functions are not run: the goroutine is
+// blocked forever instead.  If DeferOnExit is set, the goroutine is instead
+// stopped with runtime.Goexit, so its deferred functions are run before Run
+// returns.  Either way a recover in the code under test can not stop the
+// exit.
+//
+// Outside Run, Exit just records the exit code and returns, so exiting
+// functions called directly must not assume their exit calls actually
+// terminate the program.  If Exit is called more than once outside Run it
+// panics.  Under Run any later call, from some other goroutine, just stops
+// that goroutine.
 func (r *OutputRecorder) Exit(code int) {
-	if r.Exited {
-		panic("Exit called more than once; last was: " + r.ExitString())
+
+	r.mu.Lock()
+	exited := r.Exited
+	running := r.running
+	gid := r.gid
+	if !exited {
+		r.Exited = true
+		r.ExitCode = code
+		r.ExitTime = time.Now()
+	}
+	r.mu.Unlock()
+
+	if !running {
+		if exited {
+			panic("Exit called more than once; last was: " + r.ExitString())
+		}
+		return
 	}
-	r.Exited = true
-	r.ExitCode = code
-	r.ExitTime = time.Now()
+
+	// The Run goroutine unwinds itself if deferred functions are to be run;
+	// otherwise Run is told to return now.
+	unwind := r.DeferOnExit && goroutineID() == gid
+	if !exited && !unwind {
+		close(r.exit)
+	}
+	if r.DeferOnExit {
+		runtime.Goexit()
+	}
+	select {}
+}
+
+// Run runs f in a new goroutine, and returns once f returns or exits by
+// calling Exit, reporting whether it exited.  It also returns if f stops its
+// goroutine with runtime.Goexit.  If f panics, Run panics with the same value.
+//
+// Output and the exit status are recorded as usual, so an OutputRecorder is
+// good for only one Run.  Other goroutines started by f are not stopped when
+// it exits.
+func (r *OutputRecorder) Run(f func()) bool {
+
+	exit := make(chan struct{})
+	r.mu.Lock()
+	r.running = true
+	r.exit = exit
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.running = false
+		r.mu.Unlock()
+	}()
+
+	done := make(chan func(), 1) // nil, or a function repeating a panic
+	go func() {
+		returned := false
+		defer func() {
+			if !returned {
+				if p := recover(); p != nil {
+					done <- func() { panic(p) }
+					return
+				}
+			}
+			done <- nil
+		}()
+		r.mu.Lock()
+		r.gid = goroutineID()
+		r.mu.Unlock()
+		f()
+		returned = true
+	}()
+
+	select {
+	case <-exit:
+	case repanic := <-done:
+		if repanic != nil {
+			repanic()
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.Exited
 }
 
 // ExitString stringifies the exit status.
 func (r *OutputRecorder) ExitString() string {
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if !r.Exited {
 		return "did not exit"
 	}
@@ -61,12 +280,10 @@ func (r *OutputRecorder) ExitString() string {
 
 // StdoutString returns a string of all written to standard output so far.
 func (r *OutputRecorder) StdoutString() string {
-	r.Stdout.Flush()
-	return r.outBuf.String()
+	return r.streamString("stdout")
 }
 
 // StderrString returns a string of all written to standard error so far.
 func (r *OutputRecorder) StderrString() string {
-	r.Stderr.Flush()
-	return r.errBuf.String()
+	return r.streamString("stderr")
 }
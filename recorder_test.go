@@ -4,6 +4,8 @@ package testig_test
 
 import (
 	"fmt"
+	"io"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -76,3 +78,145 @@ func Test_OutputRecorder_StderrString(t *testing.T) {
 	assert.Equal("first line\nsecond line\n", r.StderrString(),
 		"StderrString returns buffer string")
 }
+
+func Test_OutputRecorder_Run_Returns(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	exited := r.Run(func() { fmt.Fprint(r.Stdout, "hello") })
+	assert.False(exited, "did not exit")
+	assert.False(r.Exited, "Exited not set")
+	assert.Equal("hello", r.StdoutString(), "output recorded")
+
+}
+
+func Test_OutputRecorder_Run_Exit(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	deferred := false
+	exited := r.Run(func() {
+		defer func() { deferred = true }()
+		defer func() { recover() }()
+		fmt.Fprint(r.Stderr, "failing")
+		r.Exit(2)
+		fmt.Fprint(r.Stderr, " and still going")
+		r.Exit(3)
+	})
+	assert.True(exited, "exited")
+	assert.Equal(2, r.ExitCode, "first exit code")
+	assert.Equal("failing", r.StderrString(), "stopped at Exit")
+	assert.False(deferred, "deferred functions not run")
+
+}
+
+func Test_OutputRecorder_Run_DeferOnExit(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	r.DeferOnExit = true
+	exited := r.Run(func() {
+		defer fmt.Fprint(r.Stdout, "deferred")
+		defer func() { recover() }()
+		r.Exit(1)
+		fmt.Fprint(r.Stdout, "unreached")
+	})
+	assert.True(exited, "exited")
+	assert.Equal(1, r.ExitCode, "exit code")
+	assert.Equal("deferred", r.StdoutString(),
+		"deferred functions run before Run returns")
+
+}
+
+func Test_OutputRecorder_Run_ExitInGoroutine(t *testing.T) {
+
+	for _, deferOnExit := range []bool{false, true} {
+		r := testig.NewOutputRecorder()
+		r.DeferOnExit = deferOnExit
+		exited := r.Run(func() {
+			go r.Exit(4)
+			select {}
+		})
+		assert.True(t, exited, "exited, DeferOnExit %v", deferOnExit)
+		assert.Equal(t, 4, r.ExitCode, "exit code, DeferOnExit %v",
+			deferOnExit)
+	}
+
+}
+
+func Test_OutputRecorder_Run_Panic(t *testing.T) {
+
+	r := testig.NewOutputRecorder()
+	testig.AssertPanicsWith(t, func() { r.Run(func() { panic("boom") }) },
+		"boom", "panic repeated")
+	assert.False(t, r.Run(runtime.Goexit), "Goexit returns")
+
+}
+
+func Test_OutputRecorder_Writes(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	fmt.Fprint(r.Stderr, "warning: no config\n")
+	fmt.Fprint(r.Stdout, "result: ")
+	r.Stdout.Write(nil)
+	r.Stdout.WriteString("42\n")
+	assert.NoError(r.Stdout.Flush(), "Flush does nothing")
+
+	writes := r.Writes()
+	if assert.Equal(3, len(writes), "empty write not recorded") {
+		for i, w := range writes {
+			assert.Equal(i, w.Seq, "Seq")
+			assert.False(w.Time.IsZero(), "Time set")
+		}
+		assert.Equal("stderr", writes[0].Stream, "first Stream")
+		assert.Equal([]byte("result: "), writes[1].Data, "second Data")
+	}
+
+	assert.Equal("result: 42\n", r.StdoutString(), "stdout only")
+	assert.Equal("warning: no config\nresult: 42\n", r.CombinedString(),
+		"interleaved")
+	assert.Equal(`stderr: "warning: no config\n"
+stdout: "result: "
+stdout: "42\n"
+`, r.Transcript(), "Transcript")
+
+}
+
+func Test_OutputStream_BufioMethods(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	assert.NoError(r.Stdout.WriteByte('>'), "WriteByte")
+	n, err := r.Stdout.WriteRune('é')
+	assert.NoError(err, "WriteRune")
+	assert.Equal(2, n, "WriteRune bytes")
+	r.Stdout.WriteString("\n")
+	assert.NoError(r.Stdout.Flush(), "Flush")
+	assert.Equal(">é\n", r.StdoutString(), "all written")
+	assert.Equal(3, len(r.Writes()), "each recorded")
+
+}
+
+func Test_OutputRecorder_Writes_Concurrent(t *testing.T) {
+
+	r := testig.NewOutputRecorder()
+	done := make(chan bool)
+	for _, w := range []io.Writer{r.Stdout, r.Stderr} {
+		go func(w io.Writer) {
+			for i := 0; i < 100; i++ {
+				fmt.Fprint(w, "x")
+			}
+			done <- true
+		}(w)
+	}
+	<-done
+	<-done
+	assert.Equal(t, 200, len(r.Writes()), "all writes recorded")
+
+}
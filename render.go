@@ -0,0 +1,94 @@
+// render.go -- go test style output.
+
+package testig
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// footer returns the result line of tt as printed by go test, without
+// indentation.
+func (tt *TestTester) footer() string {
+	return fmt.Sprintf("--- %s: %s (%.2fs)\n", tt.result(), tt.name, tt.elapsed())
+}
+
+// Output returns the output of tt and all its subtests exactly as go test
+// would print it, less the package-level lines at the end.  If verbose is
+// true the output is that of go test -v:
+//
+//	=== RUN   TestTester
+//	    file_test.go:12: logged by TestTester
+//	=== RUN   TestTester/sub
+//	    file_test.go:14: logged by TestTester/sub
+//	--- FAIL: TestTester (0.00s)
+//	    --- FAIL: TestTester/sub (0.00s)
+//
+// Otherwise it is that of plain go test, which only prints failures:
+//
+//	--- FAIL: TestTester (0.00s)
+//	    file_test.go:12: logged by TestTester
+//	    --- FAIL: TestTester/sub (0.00s)
+//	        file_test.go:14: logged by TestTester/sub
+//
+// In either case tt is treated as a top-level test even if it is a subtest.
+// Parallel subtests are shown paused and continued as go test shows them,
+// and as if they had run one at a time.
+func (tt *TestTester) Output(verbose bool) string {
+
+	// As in testing, each test's report is added to its parent's buffered
+	// output when it is done, indented, and the top-level report printed.
+	lines := []string{}
+	buffered := map[*TestTester][]string{}
+	report := func(t *TestTester, out []string) {
+		if t == tt {
+			lines = append(lines, out...)
+			return
+		}
+		for _, line := range out {
+			buffered[t.parent] = append(buffered[t.parent], "    "+line)
+		}
+	}
+
+	last := ""
+	frame := func(t *TestTester, line string) {
+		if verbose {
+			lines = append(lines, line)
+			last = t.name
+		}
+	}
+
+	tt.stream(func(kind streamKind, t *TestTester, ev *Event) {
+		switch kind {
+		case streamRun:
+			frame(t, "=== RUN   "+t.name+"\n")
+		case streamEvent:
+			out := decorate(*ev)
+			if !verbose {
+				buffered[t] = append(buffered[t], out...)
+			} else if len(out) > 0 {
+				if last != t.name {
+					frame(t, "=== NAME  "+t.name+"\n")
+				}
+				lines = append(lines, out...)
+			}
+		case streamPause:
+			frame(t, "=== PAUSE "+t.name+"\n")
+		case streamCont:
+			frame(t, "=== CONT  "+t.name+"\n")
+		case streamDone:
+			if verbose || t.Failed() {
+				report(t, append([]string{t.footer()}, buffered[t]...))
+			}
+			delete(buffered, t)
+		}
+	})
+	return strings.Join(lines, "")
+}
+
+// WriteOutput writes the Output of tt to w.
+func (tt *TestTester) WriteOutput(w io.Writer, verbose bool) error {
+	_, err := io.WriteString(w, tt.Output(verbose))
+	return err
+}
@@ -0,0 +1,111 @@
+// render_test.go
+
+package testig_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+// runTestRender does with a TestTester what this does with go test:
+//
+//	func TestRender(t *testing.T) {
+//		t.Log("hello\nworld")
+//		t.Run("sub one", func(t *testing.T) {
+//			t.Error("bad")
+//			t.Run("deep", func(t *testing.T) { t.Log("x") })
+//		})
+//		t.Log("after")
+//		t.Run("skip", func(t *testing.T) { t.Skip("later") })
+//	}
+func runTestRender() *testig.TestTester {
+	tt := testig.NewNamedTestTester("TestRender")
+	tt.RunHelper(func(t testig.TT) {
+		t.Log("hello\nworld")
+		testig.RunSubtest(t, "sub one", func(t testig.TT) {
+			t.Error("bad")
+			testig.RunSubtest(t, "deep", func(t testig.TT) { t.Log("x") })
+		})
+		t.Log("after")
+		testig.RunSubtest(t, "skip", func(t testig.TT) { t.Skip("later") })
+	})
+	return tt
+}
+
+var fileLineRegexp = regexp.MustCompile(`\S+\.go:\d+:`)
+
+// anyFileLine makes output comparable regardless of where it was logged.
+func anyFileLine(s string) string {
+	return fileLineRegexp.ReplaceAllString(s, "x_test.go:1:")
+}
+
+func Test_TestTester_Output_Verbose(t *testing.T) {
+
+	// As printed by go test -v, less the package-level lines.
+	exp := `=== RUN   TestRender
+    x_test.go:1: hello
+        world
+=== RUN   TestRender/sub_one
+    x_test.go:1: bad
+=== RUN   TestRender/sub_one/deep
+    x_test.go:1: x
+=== NAME  TestRender
+    x_test.go:1: after
+=== RUN   TestRender/skip
+    x_test.go:1: later
+--- FAIL: TestRender (0.00s)
+    --- FAIL: TestRender/sub_one (0.00s)
+        --- PASS: TestRender/sub_one/deep (0.00s)
+    --- SKIP: TestRender/skip (0.00s)
+`
+	tt := runTestRender()
+	assert.Equal(t, exp, anyFileLine(tt.Output(true)), "verbose output")
+
+}
+
+func Test_TestTester_Output_Plain(t *testing.T) {
+
+	// As printed by go test, less the package-level lines.
+	exp := `--- FAIL: TestRender (0.00s)
+    x_test.go:1: hello
+        world
+    --- FAIL: TestRender/sub_one (0.00s)
+        x_test.go:1: bad
+    x_test.go:1: after
+`
+	tt := runTestRender()
+	assert.Equal(t, exp, anyFileLine(tt.Output(false)), "plain output")
+
+	tt = testig.NewNamedTestTester("TestPass")
+	tt.RunHelper(func(t testig.TT) { t.Log("fine") })
+	assert.Equal(t, "", tt.Output(false), "no output if passed")
+
+}
+
+func Test_TestTester_Output_FileLine(t *testing.T) {
+
+	tt := testig.NewNamedTestTester("TestLine")
+	tt.RunHelper(func(t testig.TT) { t.Error("oops") })
+	line := here() - 1
+	exp := fmt.Sprintf("--- FAIL: TestLine (0.00s)\n"+
+		"    render_test.go:%d: oops\n", line)
+	assert.Equal(t, exp, tt.Output(false), "file and line as go test")
+
+}
+
+func Test_TestTester_WriteOutput(t *testing.T) {
+
+	tt := testig.NewNamedTestTester("TestSkip")
+	tt.SkipNow()
+	buf := &bytes.Buffer{}
+	assert.NoError(t, tt.WriteOutput(buf, true), "no error")
+	assert.Equal(t, "=== RUN   TestSkip\n--- SKIP: TestSkip (0.00s)\n",
+		buf.String(), "written")
+
+}
@@ -0,0 +1,146 @@
+// reset.go -- reusing TestTesters.
+
+package testig
+
+import "time"
+
+// Reset returns tt to the state of a new TestTester, so that it can be
+// reused.  Any pending Cleanup functions are run first.  The name and the
+// DetectMisuse, Timeout and MaxParallel properties are kept, as is tt's place
+// in its tree if it is a subtest; everything else, including its own
+// subtests, is forgotten.
+//
+// For a BenchTester or FuzzTester only the embedded TestTester is reset.
+//
+// Reset panics if called while a function is being run by RunHelper.
+func (tt *TestTester) Reset() {
+
+	tt.mu.RLock()
+	running := len(tt.runs) > 0
+	tt.mu.RUnlock()
+	if running {
+		panic("testig: Reset called while running")
+	}
+	tt.RunCleanups()
+
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	tt.Logs = []string{}
+	tt.Events = nil
+	tt.Stopped = false
+	tt.Panic = nil
+	tt.failed = false
+	tt.skipped = false
+	tt.completed = false
+	tt.misuses = nil
+	tt.stops = 0
+	tt.panics = 0
+	tt.started = time.Time{}
+	tt.finished = time.Time{}
+	tt.children = nil
+	tt.subNames = nil
+	tt.ctx = nil
+	tt.cancel = nil
+	tt.helpers = nil
+	tt.parallel = false
+	tt.denyParallel = false
+	tt.parallelIdx = 0
+	tt.pauseTime = time.Time{}
+	tt.contTime = time.Time{}
+}
+
+// Snapshot marks the state of a TestTester at a point in time, so that
+// changes since then can be had from Diff.
+type Snapshot struct {
+	events   int
+	logs     int
+	subtests int
+	misuses  int
+	stops    int
+	panics   int
+	failed   bool
+	skipped  bool
+}
+
+// Snapshot returns a Snapshot of the current state of tt, for Diff:
+//
+//	for _, c := range cases {
+//	    snap := tt.Snapshot()
+//	    MyHelper(tt, c.input)
+//	    assert.Equal(t, c.exp, tt.Diff(snap).Outcome)
+//	}
+func (tt *TestTester) Snapshot() Snapshot {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+	return Snapshot{
+		events:   len(tt.Events),
+		logs:     len(tt.Logs),
+		subtests: len(tt.children),
+		misuses:  len(tt.misuses),
+		stops:    tt.stops,
+		panics:   tt.panics,
+		failed:   tt.failed,
+		skipped:  tt.skipped,
+	}
+}
+
+// Changes describes what happened to a TestTester between a Snapshot and a
+// call to Diff.
+//
+// The Outcome is that of the changes alone: Logs has only the messages
+// logged since the Snapshot, and each flag is true only if something since
+// then set it, even if it was already set before.  Thus Failed is true if an
+// Error, Fatal or Fail Event was recorded, a subtest failed, a misuse was
+// detected, a function panicked or the test was otherwise marked as failed;
+// Skipped is true if an EventSkip was recorded; Stopped if the test was
+// stopped by FailNow, SkipNow etc.; and Panicked if a function run by
+// RunHelper panicked.
+type Changes struct {
+	Outcome
+	Events   []Event
+	Subtests []*TestTester
+}
+
+// Diff returns the Changes to tt since prev, which must have been taken by
+// Snapshot on tt since it was last Reset.
+func (tt *TestTester) Diff(prev Snapshot) Changes {
+
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
+	c := Changes{
+		Outcome: Outcome{
+			Logs:     tail(tt.Logs, prev.logs),
+			Failed:   tt.failed && !prev.failed,
+			Skipped:  tt.skipped && !prev.skipped,
+			Stopped:  tt.stops > prev.stops,
+			Panicked: tt.panics > prev.panics,
+		},
+		Events:   tail(tt.Events, prev.events),
+		Subtests: tail(tt.children, prev.subtests),
+	}
+
+	if c.Panicked || len(tt.misuses) > prev.misuses {
+		c.Failed = true
+	}
+	for _, ev := range c.Events {
+		switch ev.Kind {
+		case EventError, EventFatal, EventFail:
+			c.Failed = true
+		case EventSkip:
+			c.Skipped = true
+		}
+	}
+	for _, sub := range c.Subtests {
+		if sub.Failed() {
+			c.Failed = true
+		}
+	}
+	return c
+}
+
+// tail returns a copy of s from index i on, or an empty slice if there is
+// nothing there.
+func tail[T any](s []T, i int) []T {
+	return append([]T{}, s[min(i, len(s)):]...)
+}
@@ -0,0 +1,135 @@
+// reset_test.go
+
+package testig_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+func Test_TestTester_Reset(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewNamedTestTester("Reused")
+	tt.DetectMisuse = true
+	tt.Timeout = time.Minute
+	tt.MaxParallel = 2
+	var dir string
+	tt.RunHelper(func(t testig.TT) {
+		dir = t.(testig.TT2).TempDir()
+		testig.RunSubtest(t, "sub", func(t testig.TT) { t.Skip("later") })
+		t.(testig.TT2).Helper()
+		panic("boom")
+	})
+	tt.Cleanup(func() { t.Log("pending cleanup run") })
+
+	tt.Reset()
+	testig.AssertOutcome(t, tt, testig.Outcome{}, "outcome as new")
+	assert.Nil(tt.Events, "no Events")
+	assert.Nil(tt.Panic, "no Panic")
+	assert.Empty(tt.Subtests(), "no Subtests")
+	assert.Empty(tt.Misuses(), "no Misuses")
+	assert.Equal("", tt.Output(false), "no output")
+	_, err := os.Stat(dir)
+	assert.True(os.IsNotExist(err), "TempDir removed")
+
+	assert.Equal("Reused", tt.Name(), "Name kept")
+	assert.True(tt.DetectMisuse, "DetectMisuse kept")
+	assert.Equal(time.Minute, tt.Timeout, "Timeout kept")
+	assert.Equal(2, tt.MaxParallel, "MaxParallel kept")
+
+	tt.RunHelper(func(t testig.TT) {
+		testig.RunSubtest(t, "sub", func(t testig.TT) {})
+	})
+	assert.Equal("Reused/sub", tt.Subtests()[0].Name(),
+		"subtest names start over")
+
+}
+
+func Test_TestTester_Reset_Running(t *testing.T) {
+
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {
+		testig.AssertPanicsWith(t, tt.Reset,
+			"testig: Reset called while running")
+	})
+	assert.False(t, tt.Failed(), "panicked as expected")
+
+}
+
+func Test_TestTester_Diff(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	snap := tt.Snapshot()
+	c := tt.Diff(snap)
+	assert.Equal(testig.Outcome{Logs: []string{}}, c.Outcome, "no changes")
+	assert.Empty(c.Events, "no Events")
+	assert.Empty(c.Subtests, "no Subtests")
+
+	tt.Fatal("first")
+	c = tt.Diff(snap)
+	assert.Equal(testig.Outcome{
+		Failed:  true,
+		Stopped: true,
+		Logs:    []string{"first"},
+	}, c.Outcome, "direct Fatal")
+	if assert.Equal(1, len(c.Events), "one Event") {
+		assert.Equal(testig.EventFatal, c.Events[0].Kind, "Fatal Event")
+	}
+
+	// Failed and Stopped again, though already set.
+	snap = tt.Snapshot()
+	status := tt.RunHelper(func(t testig.TT) {
+		t.Log("second")
+		t.FailNow()
+	})
+	assert.Equal(testig.RunStopped, status, "stopped")
+	assert.Equal(testig.Outcome{
+		Failed:  true,
+		Stopped: true,
+		Logs:    []string{"second"},
+	}, tt.Diff(snap).Outcome, "run with FailNow")
+
+	snap = tt.Snapshot()
+	tt.RunHelper(func(t testig.TT) {
+		testig.RunSubtest(t, "sub", func(t testig.TT) { t.Error("third") })
+	})
+	c = tt.Diff(snap)
+	assert.Equal(testig.Outcome{Failed: true, Logs: []string{}}, c.Outcome,
+		"failed subtest")
+	if assert.Equal(1, len(c.Subtests), "one Subtest") {
+		assert.Equal([]string{"third"}, c.Subtests[0].Logs, "subtest Logs")
+	}
+
+	snap = tt.Snapshot()
+	tt.RunHelper(func(t testig.TT) { panic("fourth") })
+	assert.Equal(testig.Outcome{
+		Failed:   true,
+		Panicked: true,
+		Logs:     []string{},
+	}, tt.Diff(snap).Outcome, "panic")
+
+	snap = tt.Snapshot()
+	tt.Log("fifth")
+	tt.SkipNow()
+	assert.Equal(testig.Outcome{
+		Skipped: true,
+		Stopped: true,
+		Logs:    []string{"fifth"},
+	}, tt.Diff(snap).Outcome, "skip")
+
+	// Stale snapshot after Reset:
+	tt.Reset()
+	tt.Log("sixth")
+	assert.Equal(testig.Outcome{Logs: []string{}}, tt.Diff(snap).Outcome,
+		"nothing past stale snapshot")
+
+}
@@ -0,0 +1,265 @@
+// run.go -- running test functions under a TestTester for real.
+
+package testig
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// RunStatus describes how a function run by RunHelper ended.
+type RunStatus int
+
+const (
+	// RunReturned means the function returned normally.
+	RunReturned RunStatus = iota
+	// RunStopped means the function was stopped by FailNow or SkipNow (or by
+	// anything else that called runtime.Goexit).
+	RunStopped
+	// RunPanicked means the function panicked.
+	RunPanicked
+	// RunTimedOut means the function was still running when the Timeout
+	// expired.
+	RunTimedOut
+)
+
+// String stringifies the RunStatus.
+func (s RunStatus) String() string {
+	switch s {
+	case RunReturned:
+		return "returned"
+	case RunStopped:
+		return "stopped"
+	case RunPanicked:
+		return "panicked"
+	case RunTimedOut:
+		return "timed out"
+	default:
+		return fmt.Sprintf("RunStatus(%d)", int(s))
+	}
+}
+
+// RunHelper runs the function f in its own goroutine with the TestTester as
+// its argument, and waits for it to finish.  While f is running, FailNow and
+// SkipNow (and thus also Fatal, Skip etc.) stop its execution for real by
+// calling runtime.Goexit, exactly as testing.T does.  Only the goroutine
+// running f is stopped: called from any other goroutine they just mark the
+// test as stopped (a misuse if DetectMisuse is set).
+//
+// If f panics, the panic is recovered and its value stored in the Panic
+// property, and the test is marked as failed.
+//
+// When f has finished, any functions registered with Cleanup are run as
+// described for RunCleanups.
+//
+// If the Timeout property is set and f is still running when it expires,
+// the test is marked as failed with an error Event giving the stacks of the
+// goroutines running f and any of its subtests, and RunHelper stops waiting
+// for f: the goroutine running f can not be stopped from outside, so it is
+// left to its own devices.  Anything it or its subtests go on to log or
+// report is ignored, and FailNow or SkipNow just stops it, so the TestTester
+// can be inspected, Reset and reused at once.
+//
+// The returned RunStatus reports whether f returned normally, was stopped,
+// panicked or timed out.
+func (tt *TestTester) RunHelper(f func(TT)) RunStatus {
+	return tt.run(func() { f(tt) })
+}
+
+// runState is the state of one run of a function by RunHelper, which its
+// parallel subtests wait on.  It is guarded by the TestTester's lock.
+type runState struct {
+	barrier   chan struct{} // closed when the function has finished
+	subs      []*TestTester // parallel subtests
+	abandoned bool          // after a timeout
+}
+
+// run runs f as described for RunHelper, including the cleanup functions,
+// and keeps track of when the run started and finished.
+func (tt *TestTester) run(f func()) RunStatus {
+
+	rs := &runState{barrier: make(chan struct{})}
+	tt.mu.Lock()
+	tt.completed = false
+	tt.started = time.Now()
+	tt.current = rs
+	tt.mu.Unlock()
+
+	// Parallel subtests are part of the run, and thus of any timeout.  The
+	// run is finished with its own state, as a later run may have started
+	// by the time an abandoned one gets there.
+	done := make(chan RunStatus, 1)
+	go func() {
+		status := <-tt.start(f)
+		tt.finishParallel(rs)
+		done <- status
+	}()
+
+	var status RunStatus
+	if tt.Timeout > 0 {
+		timer := time.NewTimer(tt.Timeout)
+		select {
+		case status = <-done:
+		case <-timer.C:
+			status = RunTimedOut
+			tt.mu.Lock()
+			rs.abandoned = true
+			tt.mu.Unlock()
+			tt.timedOut()
+		}
+		timer.Stop()
+	} else {
+		status = <-done
+	}
+	tt.RunCleanups()
+
+	tt.mu.Lock()
+	tt.completed = true
+	tt.finished = time.Now()
+	tt.mu.Unlock()
+
+	return status
+}
+
+// call runs f in its own goroutine as described for RunHelper, and waits
+// for it to finish.
+func (tt *TestTester) call(f func()) RunStatus {
+	return <-tt.start(f)
+}
+
+// start starts running f in its own goroutine as described for RunHelper,
+// and returns a channel on which its RunStatus will be sent when it is done.
+func (tt *TestTester) start(f func()) <-chan RunStatus {
+
+	done := make(chan RunStatus, 1)
+
+	go func() {
+		gid := goroutineID()
+		returned := false
+		defer func() {
+			status := RunReturned
+			r := recover()
+			tt.mu.Lock()
+			if tt.abandoned[gid] {
+				// Left running after a timeout: nothing it does counts.
+				status = RunTimedOut
+				delete(tt.abandoned, gid)
+			} else if !returned {
+				if r != nil {
+					status = RunPanicked
+					tt.Panic = r
+					tt.failed = true
+					tt.panics++
+				} else {
+					status = RunStopped
+					tt.Stopped = true
+					tt.stops++
+				}
+			}
+			delete(tt.runs, gid)
+			tt.mu.Unlock()
+			done <- status
+		}()
+		tt.mu.Lock()
+		if tt.runs == nil {
+			tt.runs = map[int64]bool{}
+		}
+		tt.runs[gid] = true
+		tt.mu.Unlock()
+		f()
+		returned = true
+	}()
+
+	return done
+}
+
+// Deadline mirrors the same-named function in testing.T: it reports the time
+// at which the test will time out, and whether there is such a time.  For the
+// TestTester that is the start of the current (or last) run plus the Timeout,
+// or the earliest such deadline of a parent TestTester for subtests.
+//
+// Deadline is not part of the TT2 interface because testing.B does not have
+// it; helpers that need it can check for it:
+//
+//	if d, ok := t.(interface{ Deadline() (time.Time, bool) }); ok {
+//	    ...
+//	}
+func (tt *TestTester) Deadline() (time.Time, bool) {
+
+	var deadline time.Time
+	found := false
+	for t := tt; t != nil; t = t.parent {
+		t.mu.RLock()
+		if t.Timeout > 0 && !t.started.IsZero() {
+			d := t.started.Add(t.Timeout)
+			if !found || d.Before(deadline) {
+				deadline, found = d, true
+			}
+		}
+		t.mu.RUnlock()
+	}
+	return deadline, found
+}
+
+// timedOut marks the test as failed because it timed out, recording an error
+// Event with the stacks of the goroutines still running for it.  Those
+// goroutines are abandoned: anything they go on to record is ignored, and
+// FailNow or SkipNow just stops them.
+func (tt *TestTester) timedOut() {
+
+	ids := map[int64]bool{}
+	tt.Walk(func(t *TestTester) {
+		t.mu.Lock()
+		for id := range t.runs {
+			ids[id] = true
+			if t.abandoned == nil {
+				t.abandoned = map[int64]bool{}
+			}
+			t.abandoned[id] = true
+			delete(t.runs, id)
+		}
+		t.mu.Unlock()
+	})
+	msg := fmt.Sprintf("test timed out after %v", tt.Timeout)
+	if dump := goroutineStacks(ids); dump != "" {
+		msg += "\n\n" + dump
+	}
+
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	tt.Events = append(tt.Events, Event{
+		Kind:      EventError,
+		Message:   msg,
+		Logged:    true,
+		Time:      time.Now(),
+		Goroutine: goroutineID(),
+	})
+	tt.Logs = append(tt.Logs, msg)
+	tt.failed = true
+}
+
+// goroutineStacks returns the stack traces, as printed by runtime.Stack, of
+// the goroutines with the given IDs.
+func goroutineStacks(ids map[int64]bool) string {
+
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := []string{}
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		var id int64
+		if _, err := fmt.Sscanf(stack, "goroutine %d ", &id); err == nil && ids[id] {
+			stacks = append(stacks, strings.TrimSpace(stack))
+		}
+	}
+	return strings.Join(stacks, "\n\n")
+}
@@ -0,0 +1,293 @@
+// run_test.go
+
+package testig_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+func Test_RunStatus_String(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.Equal("returned", testig.RunReturned.String())
+	assert.Equal("stopped", testig.RunStopped.String())
+	assert.Equal("panicked", testig.RunPanicked.String())
+	assert.Equal("timed out", testig.RunTimedOut.String())
+	assert.Equal("RunStatus(99)", testig.RunStatus(99).String())
+
+}
+
+func Test_TestTester_RunHelper_Returned(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	status := tt.RunHelper(func(t testig.TT) {
+		t.Error("not fatal")
+		t.Log("still here")
+	})
+	assert.Equal(testig.RunReturned, status, "status returned")
+	assert.Equal([]string{"not fatal", "still here"}, tt.Logs,
+		"logged as expected")
+	assert.True(tt.Failed(), "Failed returns true")
+	assert.False(tt.Skipped(), "Skipped returns false")
+	assert.False(tt.Stopped, "TestTester not Stopped")
+	assert.Nil(tt.Panic, "no Panic recorded")
+
+}
+
+func Test_TestTester_RunHelper_Fatal(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	status := tt.RunHelper(func(t testig.TT) {
+		t.Fatal("stop here")
+		t.Log("should not be reached")
+	})
+	assert.Equal(testig.RunStopped, status, "status stopped")
+	assert.Equal([]string{"stop here"}, tt.Logs, "logged as expected")
+	assert.True(tt.Failed(), "Failed returns true")
+	assert.False(tt.Skipped(), "Skipped returns false")
+	assert.True(tt.Stopped, "TestTester Stopped")
+
+}
+
+func Test_TestTester_RunHelper_SkipNow(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	deferred := false
+	status := tt.RunHelper(func(t testig.TT) {
+		defer func() { deferred = true }()
+		t.SkipNow()
+		t.Error("should not be reached")
+	})
+	assert.Equal(testig.RunStopped, status, "status stopped")
+	assert.Equal([]string{}, tt.Logs, "(nothing) logged as expected")
+	assert.False(tt.Failed(), "Failed returns false")
+	assert.True(tt.Skipped(), "Skipped returns true")
+	assert.True(tt.Stopped, "TestTester Stopped")
+	assert.True(deferred, "deferred function ran")
+
+}
+
+func Test_TestTester_RunHelper_Goexit(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	status := tt.RunHelper(func(t testig.TT) {
+		runtime.Goexit()
+	})
+	assert.Equal(testig.RunStopped, status, "status stopped")
+	assert.False(tt.Failed(), "Failed returns false")
+	assert.True(tt.Stopped, "TestTester Stopped")
+
+}
+
+func Test_TestTester_RunHelper_Panic(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	status := tt.RunHelper(func(t testig.TT) {
+		panic("oh no")
+	})
+	assert.Equal(testig.RunPanicked, status, "status panicked")
+	assert.Equal("oh no", tt.Panic, "Panic recorded")
+	assert.True(tt.Failed(), "Failed returns true")
+	assert.False(tt.Stopped, "TestTester not Stopped")
+
+}
+
+func Test_TestTester_RunHelper_DirectUseAfter(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {})
+
+	// Outside of RunHelper we must not exit the calling goroutine.
+	tt.FailNow()
+	assert.True(tt.Failed(), "Failed returns true")
+	assert.True(tt.Stopped, "TestTester Stopped")
+
+}
+
+// blockForever is our helper under test that never returns.
+func blockForever(t testig.TT, ch chan struct{}) {
+	t.Log("waiting")
+	<-ch
+}
+
+func Test_TestTester_RunHelper_Timeout(t *testing.T) {
+
+	assert := assert.New(t)
+
+	ch := make(chan struct{})
+	defer close(ch)
+
+	tt := testig.NewTestTester()
+	tt.Timeout = 50 * time.Millisecond
+	cleaned := false
+	status := tt.RunHelper(func(t testig.TT) {
+		tt.Cleanup(func() { cleaned = true })
+		testig.RunSubtest(t, "sub", func(t testig.TT) {
+			blockForever(t, ch)
+		})
+	})
+	assert.Equal(testig.RunTimedOut, status, "timed out")
+	assert.True(tt.Failed(), "Failed")
+	assert.False(tt.Stopped, "not Stopped")
+	assert.True(cleaned, "cleanups run")
+	if assert.Equal(1, len(tt.Logs), "timeout logged") {
+		msg := tt.Logs[0]
+		assert.Regexp("^test timed out after 50ms\n\ngoroutine \\d+ ", msg,
+			"message and goroutine dump")
+		assert.Contains(msg, "testig_test.blockForever", "stuck helper dumped")
+		assert.Contains(msg, "(*TestTester).Run", "stuck parent dumped")
+		assert.NotContains(msg, "Test_TestTester_RunHelper_Timeout(",
+			"test goroutine not dumped")
+	}
+
+	tt = testig.NewTestTester()
+	tt.Timeout = time.Minute
+	status = tt.RunHelper(func(t testig.TT) { t.Log("quick") })
+	assert.Equal(testig.RunReturned, status, "no timeout if returned")
+
+}
+
+func Test_TestTester_RunHelper_TimeoutAbandoned(t *testing.T) {
+
+	assert := assert.New(t)
+
+	for _, withCleanup := range []bool{false, true} {
+		release := make(chan struct{})
+		finished := make(chan struct{})
+		ranPast := false
+		tt := testig.NewTestTester()
+		tt.Timeout = 50 * time.Millisecond
+		status := tt.RunHelper(func(t testig.TT) {
+			defer close(finished)
+			if withCleanup {
+				t.(testig.TT2).Cleanup(func() {})
+			}
+			<-release
+			t.Log("too late")
+			t.Fatal("too late")
+			ranPast = true
+		})
+		assert.Equal(testig.RunTimedOut, status, "timed out")
+
+		tt.FailNow() // would end this test if it stopped the caller
+		assert.True(tt.Stopped, "direct FailNow after timeout")
+		assert.NotPanics(tt.Reset, "Reset after timeout")
+
+		close(release)
+		<-finished
+		assert.False(ranPast, "abandoned goroutine stopped by Fatal")
+		assert.Empty(tt.Logs, "late Log ignored")
+		assert.Empty(tt.Events, "late Events ignored")
+		assert.False(tt.Failed(), "late Fatal ignored")
+		assert.False(tt.Stopped, "late Fatal does not stop")
+	}
+
+}
+
+func Test_TestTester_RunHelper_TimeoutReused(t *testing.T) {
+
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	tt := testig.NewTestTester()
+	tt.Timeout = 50 * time.Millisecond
+	tt.RunHelper(func(t testig.TT) {
+		defer close(finished)
+		<-release
+	})
+	tt.Reset()
+	tt.Timeout = 0
+
+	var mu sync.Mutex
+	order := []string{}
+	note := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, s)
+	}
+	tt.RunHelper(func(t testig.TT) {
+		tt.Run("sub", func(t testig.TT) {
+			testig.Parallel(t)
+			time.Sleep(20 * time.Millisecond)
+			note("sub done")
+		})
+		close(release) // the abandoned run finishes during this one
+		<-finished
+		time.Sleep(10 * time.Millisecond)
+	})
+	note("run returned")
+	assert.Equal([]string{"sub done", "run returned"}, order,
+		"parallel subtest finished by its own run")
+
+}
+
+func Test_TestTester_RunHelper_TimeoutSubtests(t *testing.T) {
+
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	tt := testig.NewTestTester()
+	tt.Timeout = 50 * time.Millisecond
+	tt.RunHelper(func(t testig.TT) {
+		defer close(finished)
+		<-release
+		testig.RunSubtest(t, "late", func(t testig.TT) { t.Error("late") })
+	})
+	tt.Reset()
+	close(release)
+	<-finished
+	assert.False(tt.Failed(), "late subtest failure ignored")
+	assert.Empty(tt.Subtests(), "late subtest detached")
+
+}
+
+func Test_TestTester_Deadline(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	_, ok := tt.Deadline()
+	assert.False(ok, "no deadline without Timeout")
+
+	tt.Timeout = time.Hour
+	_, ok = tt.Deadline()
+	assert.False(ok, "no deadline before run")
+
+	var parent, sub time.Time
+	var parentOK, subOK bool
+	before := time.Now()
+	tt.RunHelper(func(t testig.TT) {
+		parent, parentOK = tt.Deadline()
+		tt.Run("sub", func(t testig.TT) {
+			sub, subOK = t.(*testig.TestTester).Deadline()
+		})
+	})
+	assert.True(parentOK, "deadline in run")
+	assert.True(subOK, "deadline in subtest")
+	assert.WithinDuration(before.Add(time.Hour), parent, time.Second,
+		"deadline set from Timeout")
+	assert.Equal(parent, sub, "subtest inherits deadline")
+
+}
@@ -0,0 +1,298 @@
+// spy.go -- recording calls to a real TT.
+
+package testig
+
+import "fmt"
+
+// Spy is a TT that forwards every call to another TT, typically a real
+// *testing.T, while recording the same Events and state as a TestTester.
+// This lets a helper be run against the real thing while its effects are
+// checked just as for a TestTester:
+//
+//	func TestMyHelper(t *testing.T) {
+//	    spy := testig.NewSpy(t)
+//	    MyHelper(spy, "some input")
+//	    assert.Equal(t, []string{"expected log"}, spy.Logs)
+//	}
+//
+// If Swallow is set, failures and skips are recorded but not forwarded, so
+// that a test can check that a helper fails without failing itself.  Only
+// Log and Logf are forwarded in that case.
+//
+// Outside of RunHelper, FailNow, Fatal, Fatalf, SkipNow, Skip and Skipf are
+// forwarded directly, as the same methods of T.  Under RunHelper they stop
+// the helper as with a TestTester, and since testing.T only allows stopping
+// in the test's own goroutine they are split: any message is forwarded at
+// once to T's Error, Errorf, Log or Logf, and the stop to T's FailNow or
+// SkipNow only once the helper is done.  A panic in the helper is likewise
+// passed on.
+//
+// Where T has a Helper method it is called directly by every forwarding
+// method, so T reports the location of the call to the Spy.  Helper calls
+// made on the Spy itself are only recorded by its TestTester, however, so T
+// may report a location inside a helper that the Spy's Events do not.
+//
+// Setenv, Chdir, TempDir, Cleanup and Context are those of the TestTester,
+// not forwarded.  So that their effects are undone even when the Spy is
+// used directly, NewSpy has T run the Spy's Cleanup functions, if T has a
+// Cleanup method as *testing.T does.
+type Spy struct {
+	*TestTester
+	T       TT
+	Swallow bool
+}
+
+// NewSpy returns a new Spy forwarding to t.  If t has a Name method, as
+// *testing.T does, then the Spy has the same name; otherwise it is named
+// DefaultTestTesterName.  If t has a Cleanup method then the Spy's Cleanup
+// functions are run by t's, as described above.
+func NewSpy(t TT) *Spy {
+	s := &Spy{TestTester: NewNamedTestTester(nameOf(t)), T: t}
+	if c, ok := t.(interface{ Cleanup(func()) }); ok {
+		c.Cleanup(s.RunCleanups)
+	}
+	return s
+}
+
+// nameOf returns the name of t if it has a Name method, and otherwise
+// DefaultTestTesterName.
+func nameOf(t TT) string {
+	if n, ok := t.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	return DefaultTestTesterName
+}
+
+// Error records an EventError and forwards the call to T.
+func (s *Spy) Error(args ...interface{}) {
+	s.record(EventError, sprintArgs(args), true)
+	if !s.Swallow {
+		if h, ok := s.T.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		s.T.Error(args...)
+	}
+}
+
+// Errorf records an EventError and forwards the call to T.
+func (s *Spy) Errorf(format string, args ...interface{}) {
+	s.record(EventError, fmt.Sprintf(format, args...), true)
+	if !s.Swallow {
+		if h, ok := s.T.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		s.T.Errorf(format, args...)
+	}
+}
+
+// Fail records an EventFail and forwards the call to T.
+func (s *Spy) Fail() {
+	s.record(EventFail, "", false)
+	if !s.Swallow {
+		if h, ok := s.T.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		s.T.Fail()
+	}
+}
+
+// FailNow records an EventFail and stops the Spy, forwarding the call to T
+// as described above.
+func (s *Spy) FailNow() {
+	s.record(EventFail, "", false)
+	if s.forwardNow() {
+		if h, ok := s.T.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		s.TestTester.stop()
+		s.T.FailNow()
+		return
+	}
+	s.TestTester.stop()
+}
+
+// Fatal records an EventFatal and stops the Spy, forwarding the call to T as
+// described above: under RunHelper the message goes to T's Error.
+func (s *Spy) Fatal(args ...interface{}) {
+	s.record(EventFatal, sprintArgs(args), true)
+	if s.forwardNow() {
+		if h, ok := s.T.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		s.TestTester.stop()
+		s.T.Fatal(args...)
+		return
+	}
+	if !s.Swallow {
+		if h, ok := s.T.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		s.T.Error(args...)
+	}
+	s.TestTester.stop()
+}
+
+// Fatalf records an EventFatal and stops the Spy, forwarding the call to T
+// as described above: under RunHelper the message goes to T's Errorf.
+func (s *Spy) Fatalf(format string, args ...interface{}) {
+	s.record(EventFatal, fmt.Sprintf(format, args...), true)
+	if s.forwardNow() {
+		if h, ok := s.T.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		s.TestTester.stop()
+		s.T.Fatalf(format, args...)
+		return
+	}
+	if !s.Swallow {
+		if h, ok := s.T.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		s.T.Errorf(format, args...)
+	}
+	s.TestTester.stop()
+}
+
+// Log records an EventLog and forwards the call to T.
+func (s *Spy) Log(args ...interface{}) {
+	s.record(EventLog, sprintArgs(args), true)
+	if h, ok := s.T.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	s.T.Log(args...)
+}
+
+// Logf records an EventLog and forwards the call to T.
+func (s *Spy) Logf(format string, args ...interface{}) {
+	s.record(EventLog, fmt.Sprintf(format, args...), true)
+	if h, ok := s.T.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	s.T.Logf(format, args...)
+}
+
+// Skip records an EventSkip and stops the Spy, forwarding the call to T as
+// described above: under RunHelper the message goes to T's Log.
+func (s *Spy) Skip(args ...interface{}) {
+	s.record(EventSkip, sprintArgs(args), true)
+	if s.forwardNow() {
+		if h, ok := s.T.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		s.TestTester.stop()
+		s.T.Skip(args...)
+		return
+	}
+	if !s.Swallow {
+		if h, ok := s.T.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		s.T.Log(args...)
+	}
+	s.TestTester.stop()
+}
+
+// SkipNow records an EventSkip and stops the Spy, forwarding the call to T
+// as described above.
+func (s *Spy) SkipNow() {
+	s.record(EventSkip, "", false)
+	if s.forwardNow() {
+		if h, ok := s.T.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		s.TestTester.stop()
+		s.T.SkipNow()
+		return
+	}
+	s.TestTester.stop()
+}
+
+// Skipf records an EventSkip and stops the Spy, forwarding the call to T as
+// described above: under RunHelper the message goes to T's Logf.
+func (s *Spy) Skipf(format string, args ...interface{}) {
+	s.record(EventSkip, fmt.Sprintf(format, args...), true)
+	if s.forwardNow() {
+		if h, ok := s.T.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		s.TestTester.stop()
+		s.T.Skipf(format, args...)
+		return
+	}
+	if !s.Swallow {
+		if h, ok := s.T.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		s.T.Logf(format, args...)
+	}
+	s.TestTester.stop()
+}
+
+// forwardNow reports whether a stop is to be forwarded to T at once, as it
+// is unless Swallow is set or the Spy is running a function with RunHelper.
+// A goroutine left running after a timeout counts as running.
+func (s *Spy) forwardNow() bool {
+	if s.Swallow {
+		return false
+	}
+	gid := goroutineID()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.runs) == 0 && !s.abandoned[gid]
+}
+
+// forwardStop calls SkipNow on T if the Spy was skipped, and otherwise
+// FailNow.
+func (s *Spy) forwardStop() {
+	if s.Skipped() {
+		s.T.SkipNow()
+	} else {
+		s.T.FailNow()
+	}
+}
+
+// RunHelper runs f with the Spy as its argument, as described for the
+// TestTester's RunHelper.  Unless Swallow is set, a stop or timeout is then
+// forwarded to T and a panic is re-panicked.
+func (s *Spy) RunHelper(f func(TT)) RunStatus {
+	status := s.run(func() { f(s) })
+	if !s.Swallow {
+		switch status {
+		case RunPanicked:
+			panic(s.Panic)
+		case RunStopped:
+			s.forwardStop()
+		case RunTimedOut:
+			if h, ok := s.T.(interface{ Helper() }); ok {
+				h.Helper()
+			}
+			s.T.Errorf("test timed out after %v", s.Timeout)
+			s.T.FailNow()
+		}
+	}
+	return status
+}
+
+// Parallel forwards the call to T, if T has a Parallel method, without
+// recording anything.  This lets a helper run by a Spy on a real *testing.T
+// be a parallel subtest.
+func (s *Spy) Parallel() {
+	Parallel(s.T)
+}
+
+// Run runs f as a subtest of T called name, with a new Spy on the subtest's
+// TT, and reports whether f succeeded according to the new Spy.  The Spy's
+// TestTester is a subtest of this one's, just as for the TestTester's Run.
+func (s *Spy) Run(name string, f func(TT)) bool {
+
+	var sub *Spy
+	RunSubtest(s.T, name, func(t TT) {
+		sub = &Spy{TestTester: s.newSubtest(name), T: t, Swallow: s.Swallow}
+		sub.RunHelper(f)
+	})
+	if sub == nil || !sub.Failed() {
+		return true
+	}
+	s.subtestFailed(sub.TestTester)
+	return false
+}
@@ -0,0 +1,218 @@
+// spy_test.go
+
+package testig_test
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+// Implements TT, and works with RunSubtest.
+var _ testig.TT = (*testig.Spy)(nil)
+
+func Test_NewSpy(t *testing.T) {
+
+	assert := assert.New(t)
+
+	spy := testig.NewSpy(t)
+	assert.Equal(t.Name(), spy.Name(), "named as t")
+	assert.Equal(t, spy.T, "T set")
+	assert.False(spy.Swallow, "not swallowing")
+
+	nameless := struct{ testig.TT }{testig.NewTestTester()}
+	spy = testig.NewSpy(nameless)
+	assert.Equal(testig.DefaultTestTesterName, spy.Name(), "default name")
+
+}
+
+func Test_Spy_Forwards(t *testing.T) {
+
+	assert := assert.New(t)
+
+	inner := testig.NewTestTester()
+	spy := testig.NewSpy(inner)
+	spy.Log("one")
+	spy.Logf("t%s", "wo")
+	spy.Error("three")
+	line := here() - 1
+	spy.Errorf("fo%s", "ur")
+	spy.Fail()
+
+	exp := []string{"one", "two", "three", "four"}
+	assert.Equal(exp, spy.Logs, "recorded")
+	assert.Equal(exp, inner.Logs, "forwarded")
+	assert.True(spy.Failed(), "spy Failed")
+	assert.True(inner.Failed(), "inner Failed")
+	assert.Equal(5, len(spy.Events), "events recorded")
+	assert.Equal(5, len(inner.Events), "events forwarded")
+	assert.Equal(line, spy.Events[2].Line, "recorded at call")
+	assert.Equal(line, inner.Events[2].Line, "forwarded at call")
+
+}
+
+func Test_Spy_Forwards_Stop(t *testing.T) {
+
+	assert := assert.New(t)
+
+	// Directly:
+	inner := testig.NewTestTester()
+	spy := testig.NewSpy(inner)
+	spy.Fatal("oops")
+	assert.True(spy.Stopped, "spy Stopped")
+	assert.True(inner.Stopped, "inner Stopped")
+	assert.Equal([]string{"oops"}, inner.Logs, "message forwarded")
+	spy.Skipf("skip %d", 1)
+	spy.FailNow()
+	spy.SkipNow()
+	kinds := []testig.EventKind{}
+	for _, ev := range inner.Events {
+		kinds = append(kinds, ev.Kind)
+	}
+	assert.Equal([]testig.EventKind{
+		testig.EventFatal,
+		testig.EventSkip,
+		testig.EventFail,
+		testig.EventSkip,
+	}, kinds, "forwarded as the same methods")
+	assert.Equal([]string{"oops", "skip 1"}, inner.Logs, "messages forwarded")
+
+	// Under RunHelper, only after the helper is stopped:
+	var spied *testig.Spy
+	inner = testig.NewTestTester()
+	inner.RunHelper(func(t testig.TT) {
+		spied = testig.NewSpy(t)
+		status := spied.RunHelper(func(t testig.TT) {
+			t.Skip("later")
+			t.Log("not reached")
+		})
+		t.Logf("not reached after %s", status)
+	})
+	assert.True(spied.Skipped(), "spy Skipped")
+	assert.True(inner.Skipped(), "inner Skipped")
+	assert.True(inner.Stopped, "inner Stopped")
+	assert.Equal([]string{"later"}, inner.Logs, "only message forwarded")
+
+}
+
+// helperCaller is a TT whose Helper method records the function calling it.
+type helperCaller struct {
+	*testig.TestTester
+	callers []string
+}
+
+func (h *helperCaller) Helper() {
+	pc, _, _, _ := runtime.Caller(1)
+	h.callers = append(h.callers, runtime.FuncForPC(pc).Name())
+}
+
+func Test_Spy_Forwards_Helper(t *testing.T) {
+
+	assert := assert.New(t)
+
+	inner := &helperCaller{TestTester: testig.NewTestTester()}
+	spy := testig.NewSpy(inner)
+	spy.Log("one")
+	spy.Errorf("two")
+	spy.Skip("three")
+	assert.Equal([]string{
+		"github.com/biztos/testig.(*Spy).Log",
+		"github.com/biztos/testig.(*Spy).Errorf",
+		"github.com/biztos/testig.(*Spy).Skip",
+	}, inner.callers, "Spy methods marked as helpers")
+
+}
+
+func Test_Spy_Cleanup(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var dir string
+	t.Run("sub", func(t *testing.T) {
+		spy := testig.NewSpy(t)
+		spy.Setenv("TESTIG_SPY_CLEANUP", "leaked")
+		dir = spy.TempDir()
+		assert.Equal("leaked", os.Getenv("TESTIG_SPY_CLEANUP"), "set")
+	})
+	_, set := os.LookupEnv("TESTIG_SPY_CLEANUP")
+	assert.False(set, "env restored by T's cleanup")
+	assert.NoDirExists(dir, "TempDir removed by T's cleanup")
+
+}
+
+func Test_Spy_RunHelper_Panic(t *testing.T) {
+
+	spy := testig.NewSpy(testig.NewTestTester())
+	testig.AssertPanicsWith(t, func() {
+		spy.RunHelper(func(t testig.TT) { panic("boom") })
+	}, "boom")
+
+	spy.Swallow = true
+	status := spy.RunHelper(func(t testig.TT) { panic("boom") })
+	assert.Equal(t, testig.RunPanicked, status, "panic swallowed")
+
+}
+
+func Test_Spy_Swallow(t *testing.T) {
+
+	assert := assert.New(t)
+
+	spy := testig.NewSpy(t)
+	spy.Swallow = true
+	status := spy.RunHelper(func(t testig.TT) {
+		t.Log("only this is forwarded")
+		t.Error("expected failure")
+		t.Fatalf("expected %s", "stop")
+	})
+	assert.Equal(testig.RunStopped, status, "stopped")
+	assert.True(spy.Failed(), "spy Failed")
+	assert.Equal([]string{
+		"only this is forwarded",
+		"expected failure",
+		"expected stop",
+	}, spy.Logs, "all recorded")
+
+	// Direct calls are swallowed too, but do not stop anything.
+	inner := testig.NewTestTester()
+	spy = testig.NewSpy(inner)
+	spy.Swallow = true
+	spy.Error("nope")
+	spy.Errorf("nope")
+	spy.Fail()
+	spy.FailNow()
+	spy.Fatal("nope")
+	spy.Fatalf("nope")
+	spy.Skip("nope")
+	spy.Skipf("nope")
+	spy.SkipNow()
+	assert.Empty(inner.Events, "nothing forwarded")
+	assert.Equal(9, len(spy.Events), "all recorded")
+
+}
+
+func Test_Spy_Run(t *testing.T) {
+
+	assert := assert.New(t)
+
+	spy := testig.NewSpy(t)
+	ok := testig.RunSubtest(spy, "sub", func(t testig.TT) {
+		t.Log("in sub")
+	})
+	assert.True(ok, "returns true")
+	sub := spy.Find(t.Name() + "/sub")
+	if assert.NotNil(sub, "subtest found") {
+		assert.Equal([]string{"in sub"}, sub.Logs, "recorded in subtest")
+	}
+
+	spy.Swallow = true
+	ok = spy.Run("fail", func(t testig.TT) {
+		t.Error("expected")
+	})
+	assert.False(ok, "returns false")
+	assert.True(spy.Failed(), "failure propagated to spy")
+
+}
@@ -0,0 +1,144 @@
+// subprocess.go -- testing main functions in child processes.
+
+package testig
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// subprocessEnv is set in the environment of a child process started by
+// RunSubprocess, to the name of the function it should run.
+const subprocessEnv = "TESTIG_SUBPROCESS"
+
+// subprocessRun is the first argument of a child process started by
+// RunSubprocess, so that it runs no tests should SubprocessMain not take
+// over.
+const subprocessRun = "-test.run=^$"
+
+// SubprocessMain runs one of funcs in place of the tests, and then exits, if
+// the current process was started by RunSubprocess; otherwise it does
+// nothing.  It must be called from TestMain before the tests are run, with
+// the functions that may be run by name:
+//
+//	func TestMain(m *testing.M) {
+//	    testig.SubprocessMain(map[string]func(){"main": main})
+//	    os.Exit(m.Run())
+//	}
+//
+// The function sees the arguments given to RunSubprocess in os.Args, after
+// the name of the test binary, just as main would see them.  If it returns,
+// the process exits with status zero, as after main.  If there is no such
+// function the process exits with status 2 and a message on standard error.
+func SubprocessMain(funcs map[string]func()) {
+
+	name, ok := os.LookupEnv(subprocessEnv)
+	if !ok {
+		return
+	}
+	f := funcs[name]
+	if f == nil {
+		fmt.Fprintf(os.Stderr, "testig: no subprocess function %q\n", name)
+		os.Exit(2)
+	}
+	os.Unsetenv(subprocessEnv) // in case f runs the binary itself
+	if len(os.Args) > 1 && os.Args[1] == subprocessRun {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+	f()
+	os.Exit(0)
+}
+
+// RunSubprocess runs the function called name, as given to SubprocessMain,
+// in a child process running the current test binary with args.  It waits
+// for the child to exit, and returns an error only if the child could not be
+// run at all:
+//
+//	r := NewOutputRecorder()
+//	r.Stdin.Feed("input\n")
+//	if err := r.RunSubprocess("main", "-verbose", "file.txt"); err != nil {
+//	    t.Fatal(err)
+//	}
+//	assert.Equal(t, 1, r.ExitCode)
+//	assert.Contains(t, r.StderrString(), "file.txt: not found")
+//
+// The child's standard output and error are recorded in Stdout and Stderr,
+// its standard input is read from Stdin until its first end of input or
+// error, either of which closes it, and its exit status is recorded as
+// for Exit: Exited is always set, and ExitCode is the child's exit code, or
+// -1 if it was killed by a signal.  The relative order of writes to the two
+// streams is only as good as their copying allows, as when capturing.
+//
+// If the test binary was built with coverage, the child's coverage data is
+// written to the directory given by -test.gocoverdir, as go test -cover
+// does, so that it is included in the coverage reported by the parent; or
+// failing that to that given by GOCOVERDIR.  If there is neither, the
+// child's coverage data is discarded.
+//
+// The child is also given -test.run=^$ ahead of args, which SubprocessMain
+// removes again, so that if SubprocessMain is missing from TestMain, or is
+// called after m.Run, the child runs no tests instead of running them all
+// over again.  For the same reason RunSubprocess returns an error at once if
+// it is called in such a child, rather than starting another.
+func (r *OutputRecorder) RunSubprocess(name string, args ...string) error {
+
+	if _, ok := os.LookupEnv(subprocessEnv); ok {
+		return fmt.Errorf("testig: RunSubprocess called in a subprocess, "+
+			"as when SubprocessMain is missing from TestMain "+
+			"or called after m.Run (%s is set)", subprocessEnv)
+	}
+
+	cmd := exec.Command(os.Args[0], append([]string{subprocessRun}, args...)...)
+	cmd.Stdout = r.Stdout
+	cmd.Stderr = r.Stderr
+	cmd.Env = append(os.Environ(), subprocessEnv+"="+name)
+
+	if testing.CoverMode() != "" {
+		dir := os.Getenv("GOCOVERDIR")
+		if f := flag.Lookup("test.gocoverdir"); f != nil && f.Value.String() != "" {
+			dir = f.Value.String()
+		}
+		if dir == "" {
+			// Avoids a warning from the child, which will write its data.
+			tmp, err := os.MkdirTemp("", "testig-cover-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(tmp)
+			dir = tmp
+		}
+		cmd.Env = append(cmd.Env, "GOCOVERDIR="+dir)
+	}
+
+	// Not cmd.Stdin, as Wait would wait for a Read to finish even after the
+	// child has exited, which for a prompt not written is all of PromptWait.
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() {
+		io.Copy(stdin, r.Stdin)
+		stdin.Close()
+	}()
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Exited = true
+	r.ExitCode = cmd.ProcessState.ExitCode()
+	r.ExitTime = time.Now()
+	return nil
+}
@@ -0,0 +1,123 @@
+// subprocess_test.go
+
+package testig_test
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+func TestMain(m *testing.M) {
+	testig.SubprocessMain(map[string]func(){"greet": greetMain})
+	os.Exit(m.Run())
+}
+
+// greetMain is a main function for the tests: it greets whoever is named on
+// standard input, and exits with the code given by the -exit flag.
+func greetMain() {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	code := fs.Int("exit", 0, "exit code")
+	fs.Parse(os.Args[1:])
+
+	fmt.Fprintln(os.Stderr, "args:", fs.Args())
+	in := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("Name? ")
+		if !in.Scan() {
+			break
+		}
+		fmt.Println("Hello,", in.Text())
+	}
+	if *code != 0 {
+		os.Exit(*code)
+	}
+}
+
+func Test_OutputRecorder_RunSubprocess(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	r.Stdin.Feed("Alice\n")
+	r.Stdin.Feed("Bob\n")
+	if !assert.NoError(r.RunSubprocess("greet", "-exit", "3", "x", "y")) {
+		return
+	}
+	assert.True(r.Exited, "Exited")
+	assert.Equal(3, r.ExitCode, "ExitCode")
+	assert.Equal("Name? Hello, Alice\nName? Hello, Bob\nName? ",
+		r.StdoutString(), "stdout")
+	assert.Equal("args: [x y]\n", r.StderrString(), "stderr")
+
+}
+
+func Test_OutputRecorder_RunSubprocess_Returns(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	r.Stdin.PromptWait = time.Minute
+	r.Stdin.Expect("Name? ", "Carol\n")
+	r.Stdin.FeedError(errors.New("ends input"))
+	r.Stdin.Feed("never read\n")
+	if !assert.NoError(r.RunSubprocess("greet")) {
+		return
+	}
+	assert.Equal(0, r.ExitCode, "zero ExitCode on return")
+	assert.Equal("Name? Hello, Carol\nName? ", r.StdoutString(),
+		"prompt answered, then input closed")
+	assert.Equal("Carol\n", r.Stdin.Consumed(), "consumed")
+
+}
+
+func Test_OutputRecorder_RunSubprocess_Missing(t *testing.T) {
+
+	r := testig.NewOutputRecorder()
+	if !assert.NoError(t, r.RunSubprocess("nonesuch")) {
+		return
+	}
+	assert.Equal(t, 2, r.ExitCode, "ExitCode")
+	assert.Equal(t, "testig: no subprocess function \"nonesuch\"\n",
+		r.StderrString(), "stderr")
+
+}
+
+func Test_OutputRecorder_RunSubprocess_PromptPending(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := testig.NewOutputRecorder()
+	r.Stdin.PromptWait = time.Minute
+	r.Stdin.Expect("never printed", "input\n")
+	start := time.Now()
+	if !assert.NoError(r.RunSubprocess("nonesuch")) {
+		return
+	}
+	assert.Equal(2, r.ExitCode, "ExitCode")
+	assert.Less(time.Since(start), 30*time.Second,
+		"returns when the child exits, not after PromptWait")
+
+}
+
+func Test_OutputRecorder_RunSubprocess_InSubprocess(t *testing.T) {
+
+	assert := assert.New(t)
+
+	t.Setenv("TESTIG_SUBPROCESS", "greet")
+	r := testig.NewOutputRecorder()
+	err := r.RunSubprocess("greet")
+	if assert.Error(err, "error in subprocess") {
+		assert.Contains(err.Error(), "RunSubprocess called in a subprocess")
+	}
+	assert.False(r.Exited, "nothing run")
+
+}
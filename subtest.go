@@ -0,0 +1,224 @@
+// subtest.go -- subtests a la testing.T.Run.
+
+package testig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// Run mirrors the same-named function in testing.T, except that f takes a TT
+// argument: it runs f as a subtest of tt called name, and reports whether f
+// succeeded.
+//
+// The subtest gets its own child TestTester, with its own Logs, which is run
+// with RunHelper and thus stopped for real by FailNow and SkipNow.  Its name
+// is the parent's name and the (rewritten, de-duplicated) subtest name
+// separated by a slash, as with testing.T.  If the subtest fails then tt is
+// also marked as failed.
+//
+// If the subtest calls Parallel, Run returns at once as described there.
+//
+// Because of its argument type this Run can not be part of the TT interface;
+// helper functions that need subtests should use RunSubtest.
+func (tt *TestTester) Run(name string, f func(TT)) bool {
+
+	sub := tt.newSubtest(name)
+	sub.pause = make(chan struct{}, 1)
+	sub.done = make(chan RunStatus, 1)
+	go func() { sub.done <- sub.RunHelper(f) }()
+
+	select {
+	case <-sub.done:
+		if sub.Failed() {
+			tt.subtestFailed(sub)
+		}
+	case <-sub.pause:
+		// Parallel: finished by finishParallel.
+	}
+	return !sub.Failed()
+}
+
+// subtest runs f as a subtest of tt called name, as described for Run but
+// without support for Parallel, and returns the subtest's TestTester and how
+// f ended.
+func (tt *TestTester) subtest(name string, f func(TT)) (*TestTester, RunStatus) {
+
+	sub := tt.newSubtest(name)
+	status := sub.RunHelper(f)
+	if sub.Failed() {
+		tt.subtestFailed(sub)
+	}
+	return sub, status
+}
+
+// subtestFailed marks tt as failed because its subtest sub failed, unless
+// sub is detached or the calling goroutine was abandoned after a timeout in
+// the meantime.
+func (tt *TestTester) subtestFailed(sub *TestTester) {
+
+	gid := goroutineID()
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if !sub.detached && !tt.abandoned[gid] {
+		tt.failed = true
+	}
+}
+
+// newSubtest returns a new child TestTester of tt for a subtest called name.
+// Called from a goroutine abandoned after a timeout, it returns a detached
+// subtest instead, which is not among tt's subtests and does not affect it.
+func (tt *TestTester) newSubtest(name string) *TestTester {
+
+	gid := goroutineID()
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	sub := &TestTester{
+		Logs:         []string{},
+		DetectMisuse: tt.DetectMisuse,
+		parent:       tt,
+		eventIdx:     len(tt.Events),
+	}
+	if tt.abandoned[gid] {
+		sub.name = tt.name + "/" + name
+		sub.detached = true
+		return sub
+	}
+	sub.name = tt.subName(name)
+	tt.children = append(tt.children, sub)
+	return sub
+}
+
+// Parent returns the TestTester of which tt is a subtest, or nil if tt is at
+// the root of its tree.
+func (tt *TestTester) Parent() *TestTester {
+	return tt.parent
+}
+
+// Subtests returns the direct subtests of tt in the order they were run.
+func (tt *TestTester) Subtests() []*TestTester {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+	subs := make([]*TestTester, len(tt.children))
+	copy(subs, tt.children)
+	return subs
+}
+
+// Walk calls f for tt and then, depth-first in the order they were run, for
+// each of its subtests.
+func (tt *TestTester) Walk(f func(*TestTester)) {
+	f(tt)
+	for _, sub := range tt.Subtests() {
+		sub.Walk(f)
+	}
+}
+
+// Find returns the TestTester in the tree under (and including) tt whose
+// full name is name, or nil if there is none.
+func (tt *TestTester) Find(name string) *TestTester {
+	if tt.name == name {
+		return tt
+	}
+	for _, sub := range tt.Subtests() {
+		if strings.HasPrefix(name, sub.name) {
+			if found := sub.Find(name); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// subName returns the full, unique name for a new subtest of tt, rewriting
+// name the same way the testing package does.  The caller must hold the lock.
+func (tt *TestTester) subName(name string) string {
+
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune('_')
+		case !strconv.IsPrint(r):
+			s := strconv.QuoteRune(r)
+			b.WriteString(s[1 : len(s)-1])
+		default:
+			b.WriteRune(r)
+		}
+	}
+	sub := b.String()
+	base := tt.name + "/" + sub
+
+	// As in testing's matcher.unique: an empty name is always numbered, and
+	// numbers are skipped where they would clash with names given explicitly
+	// with a number, as in "a#01".
+	if tt.subNames == nil {
+		tt.subNames = map[string]int{}
+	}
+	for {
+		n := tt.subNames[base]
+		tt.subNames[base] = n + 1
+		if n == 0 && sub != "" {
+			prefix, nn := parseSubtestNumber(base)
+			if len(prefix) < len(base) && nn < tt.subNames[prefix] {
+				continue
+			}
+			return base
+		}
+		name := fmt.Sprintf("%s#%02d", base, n)
+		if tt.subNames[name] != 0 {
+			continue
+		}
+		return name
+	}
+}
+
+// parseSubtestNumber splits a subtest name into the prefix and the number of
+// a "#%02d" suffix as added by subName, if it has one, as testing does; or
+// returns the name and zero.
+func parseSubtestNumber(s string) (string, int) {
+
+	i := strings.LastIndex(s, "#")
+	if i < 0 {
+		return s, 0
+	}
+	prefix, suffix := s[:i], s[i+1:]
+	if len(suffix) < 2 || (len(suffix) > 2 && suffix[0] == '0') {
+		return s, 0 // not as formatted by %02d
+	}
+	if suffix == "00" && !strings.HasSuffix(prefix, "/") {
+		return s, 0 // only empty names get #00
+	}
+	n, err := strconv.ParseInt(suffix, 10, 32)
+	if err != nil || n < 0 {
+		return s, 0
+	}
+	return prefix, int(n)
+}
+
+// RunSubtest runs f as a subtest of t called name, and reports whether f
+// succeeded.  It is the TT-friendly way for a helper to call t.Run:
+//
+//	func TableHelper(t TT) {
+//	    RunSubtest(t, "first", func(t TT) { ... })
+//	}
+//
+// RunSubtest supports *testing.T, *testing.B and anything else with a Run
+// method like the TestTester's.  For any other TT it panics.
+func RunSubtest(t TT, name string, f func(TT)) bool {
+
+	switch t := t.(type) {
+	case *testing.T:
+		return t.Run(name, func(t *testing.T) { f(t) })
+	case *testing.B:
+		return t.Run(name, func(b *testing.B) { f(b) })
+	case interface {
+		Run(string, func(TT)) bool
+	}:
+		return t.Run(name, f)
+	}
+	panic(fmt.Sprintf("RunSubtest: can not run subtests on %T", t))
+
+}
@@ -0,0 +1,149 @@
+// subtest_test.go
+
+package testig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+func Test_TestTester_Run_Success(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	ok := tt.Run("sub", func(t testig.TT) {
+		t.Log("in sub")
+	})
+	assert.True(ok, "Run returns true")
+	assert.False(tt.Failed(), "parent not Failed")
+	assert.Equal([]string{}, tt.Logs, "nothing logged in parent")
+
+	subs := tt.Subtests()
+	if assert.Equal(1, len(subs), "one subtest") {
+		sub := subs[0]
+		assert.Equal("TestTester/sub", sub.Name(), "hierarchical name")
+		assert.Equal([]string{"in sub"}, sub.Logs, "logged in sub")
+		assert.Equal(tt, sub.Parent(), "Parent set")
+	}
+
+}
+
+func Test_TestTester_Run_Failure(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewNamedTestTester("TestFoo")
+	ok := tt.Run("sub", func(t testig.TT) {
+		t.Fatal("oops")
+		t.Log("not reached")
+	})
+	assert.False(ok, "Run returns false")
+	assert.True(tt.Failed(), "failure propagated to parent")
+	assert.False(tt.Stopped, "parent not Stopped")
+
+	sub := tt.Find("TestFoo/sub")
+	if assert.NotNil(sub, "subtest found") {
+		assert.Equal([]string{"oops"}, sub.Logs, "logged in sub")
+		assert.True(sub.Failed(), "sub Failed")
+		assert.True(sub.Stopped, "sub Stopped")
+	}
+
+}
+
+func Test_TestTester_Run_Skip(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	ok := tt.Run("sub", func(t testig.TT) {
+		t.Skip("later")
+	})
+	assert.True(ok, "Run returns true for skipped subtest")
+	assert.False(tt.Failed(), "parent not Failed")
+	assert.False(tt.Skipped(), "parent not Skipped")
+	assert.True(tt.Subtests()[0].Skipped(), "sub Skipped")
+
+}
+
+func Test_TestTester_Run_Nested(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewNamedTestTester("TestFoo")
+	tt.Run("a b", func(t testig.TT) {
+		testig.RunSubtest(t, "c", func(t testig.TT) {
+			t.Error("deep")
+		})
+		testig.RunSubtest(t, "c", func(t testig.TT) {})
+		testig.RunSubtest(t, "c", func(t testig.TT) {})
+	})
+	assert.True(tt.Failed(), "failure propagated to root")
+
+	names := []string{}
+	tt.Walk(func(tt *testig.TestTester) {
+		names = append(names, tt.Name())
+	})
+	exp := []string{
+		"TestFoo",
+		"TestFoo/a_b",
+		"TestFoo/a_b/c",
+		"TestFoo/a_b/c#01",
+		"TestFoo/a_b/c#02",
+	}
+	assert.Equal(exp, names, "walked in order with rewritten names")
+
+	assert.True(tt.Find("TestFoo/a_b").Failed(), "middle Failed")
+	assert.True(tt.Find("TestFoo/a_b/c").Failed(), "leaf Failed")
+	assert.False(tt.Find("TestFoo/a_b/c#01").Failed(), "sibling not Failed")
+	assert.Nil(tt.Find("TestFoo/nope"), "Find returns nil if not found")
+
+}
+
+func Test_TestTester_Run_Names(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewNamedTestTester(t.Name())
+	for _, name := range []string{"", "", "a#01", "a", "a", "#00"} {
+		var real string
+		t.Run(name, func(t *testing.T) { real = t.Name() })
+		tt.Run(name, func(t testig.TT) {})
+		subs := tt.Subtests()
+		assert.Equal(real, subs[len(subs)-1].Name(), "named as testing does")
+	}
+	exp := []string{"/#00", "/#01", "/a#01", "/a", "/a#02", "/#00#01"}
+	for i, sub := range tt.Subtests() {
+		assert.Equal(t.Name()+exp[i], sub.Name(), "unique name")
+		assert.Equal(sub, tt.Find(sub.Name()), "found by name")
+	}
+
+}
+
+func Test_RunSubtest_TestingT(t *testing.T) {
+
+	ran := false
+	ok := testig.RunSubtest(t, "real", func(t testig.TT) {
+		ran = true
+	})
+	assert.True(t, ok, "RunSubtest returns true")
+	assert.True(t, ran, "subtest function ran")
+
+}
+
+type bareTT struct {
+	*testig.TestTester
+}
+
+func (b bareTT) Run() {}
+
+func Test_RunSubtest_PanicsOnUnsupported(t *testing.T) {
+
+	testig.AssertPanicsWith(t,
+		func() { testig.RunSubtest(bareTT{}, "x", func(testig.TT) {}) },
+		"RunSubtest: can not run subtests on testig_test.bareTT")
+
+}
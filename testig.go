@@ -3,18 +3,28 @@
 // Package testig provides helpers for testing Go programs, including itself.
 // The spelling of the package name is intentional.
 //
-// LIMITATIONS
+// STOPPING EXECUTION
 //
-// There is currently no way for a TestTester to terminate a test function
-// under test.  At the moment it doesn't seem worth the extra complexity of
-// running tests in a separate goroutine, as the workaround also happens to
-// be a useful practice: helper functions must not have any ability to
-// continue after a Fail or Skip.
+// A TestTester used directly, as in TestHelper(tt), has no way to terminate
+// the test function under test: FailNow and SkipNow only set its Stopped
+// property.  This happens to encourage a useful practice: helper functions
+// should not have any ability to continue after a Fail or Skip.
+//
+// Third-party helpers do not always follow that rule, so to stop execution
+// for real run the function under test with RunHelper, which runs it in its
+// own goroutine and exits that goroutine on FailNow or SkipNow just as the
+// testing package does.
+//
+// A function that never returns at all can be given up on by setting the
+// TestTester's Timeout before calling RunHelper.
 package testig
 
 import (
+	"context"
 	"fmt"
-	"strings"
+	"runtime"
+	"sync"
+	"time"
 )
 
 // TT defines an interface implemented by both the TestTester and testing.T
@@ -46,119 +56,231 @@ type TT interface {
 	// ...just NOT the annoying private() method
 }
 
-// TestTester implements the TT interface in a way that helps us test
+// TestTester implements the TT and TT2 interfaces in a way that helps us test
 // our test functions.  It should be used to run a single test function.
-// NOTE: it does not (yet) actually stop execution!
+// NOTE: it only actually stops execution when the function is run with
+// RunHelper.
+//
+// As with testing.T, its methods are safe for concurrent use, so helpers may
+// report from goroutines of their own.  The exported properties should only
+// be read when no test function is running, e.g. after RunHelper returns.
+//
+// If DetectMisuse is set, the TestTester also checks for the kinds of misuse
+// testing.T would not survive: calling FailNow or SkipNow (or Fatal etc.)
+// from a goroutine other than the one running the test function, and logging
+// after the test function has completed.  These are reported by Misuses and
+// mark the test as failed.
 type TestTester struct {
-	Logs    []string
-	Stopped bool
-	failed  bool
-	skipped bool
+	Logs         []string // messages of the logged Events
+	Events       []Event
+	Stopped      bool
+	Panic        interface{} // recovered by RunHelper
+	DetectMisuse bool
+	Timeout      time.Duration // for RunHelper; zero for none
+	MaxParallel  int           // for Parallel; zero for GOMAXPROCS
+
+	mu        sync.RWMutex
+	name      string
+	failed    bool
+	skipped   bool
+	completed bool
+	runs      map[int64]bool // goroutines running functions for tt, by ID
+	abandoned map[int64]bool // goroutines left running after a timeout
+	misuses   []string
+	stops     int // for Diff
+	panics    int // for Diff
+	started   time.Time
+	finished  time.Time
+	parent    *TestTester
+	detached  bool // created after a timeout, so not a child of parent
+	children  []*TestTester
+	eventIdx  int // len(parent.Events) when created
+	subNames  map[string]int
+	cleanups  []func()
+	ctx       context.Context
+	cancel    context.CancelFunc
+	helpers   map[string]bool
+	tempDir   string
+	tempSeq   int
+
+	// for Parallel
+	parallel     bool
+	denyParallel bool
+	parallelIdx  int // len(Events) when Parallel was called
+	pauseTime    time.Time
+	contTime     time.Time
+	pause        chan struct{}  // signals Run that the subtest is paused
+	done         chan RunStatus // signals Run or the parent that it is done
+	current      *runState      // of the current (or last) run
+	pstate       *parallelState // on the root only
 }
 
-// NewTestTester returns a new TestTester ready for testing tests.
+// DefaultTestTesterName is the name given to TestTesters created by
+// NewTestTester.
+const DefaultTestTesterName = "TestTester"
+
+// NewTestTester returns a new TestTester ready for testing tests.  Its name is
+// DefaultTestTesterName.
 func NewTestTester() *TestTester {
+	return NewNamedTestTester(DefaultTestTesterName)
+}
+
+// NewNamedTestTester returns a new TestTester with the given name, which is
+// used as the root of the names of any subtests.
+func NewNamedTestTester(name string) *TestTester {
 	return &TestTester{
 		Logs: []string{},
+		name: name,
 	}
 }
 
+// Name mirrors the same-named function in testing.T: it returns the name of
+// the running test or subtest.
+func (tt *TestTester) Name() string {
+	return tt.name
+}
+
 // Error mirrors the same-named function in testing.T: it is equivalent to Log
 // followed by Fail.
 func (tt *TestTester) Error(args ...interface{}) {
-	tt.Log(args...)
-	tt.Fail()
+	tt.record(EventError, sprintArgs(args), true)
 
 }
 
 // Errorf mirrors the same-named function in testing.T: it is equivalent to
 // Logf followed by Fail.
 func (tt *TestTester) Errorf(format string, args ...interface{}) {
-	tt.Logf(format, args...)
-	tt.Fail()
+	tt.record(EventError, fmt.Sprintf(format, args...), true)
 }
 
 // Fail mirrors the same-named function in testing.T: it marks the function as
 // having failed but continues execution.
 func (tt *TestTester) Fail() {
-	tt.failed = true
+	tt.record(EventFail, "", false)
 }
 
 // FailNow mirrors the same-named function in testing.T: it marks the
 // function as having failed and sets the TestTester's Stopped property to
-// true.
+// true.  Under RunHelper it also stops execution by calling runtime.Goexit.
 func (tt *TestTester) FailNow() {
-	tt.Fail()
-	tt.Stopped = true // TODO: stop execution for real (exit goroutine)
+	tt.record(EventFail, "", false)
+	tt.stop()
 }
 
 // Failed mirrors the same-named function in testing.T: it reports whether the
 // function has failed.
 func (tt *TestTester) Failed() bool {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
 	return tt.failed
 }
 
 // Fatal mirrors the same-named function in testing.T: it is equivalent to Log
 // followed by FailNow.
 func (tt *TestTester) Fatal(args ...interface{}) {
-	tt.Log(args...)
-	tt.FailNow()
+	tt.record(EventFatal, sprintArgs(args), true)
+	tt.stop()
 }
 
 // Fatalf mirrors the same-named function in testing.T: it is equivalent to
 // Logf followed by FailNow.
 func (tt *TestTester) Fatalf(format string, args ...interface{}) {
-	tt.Logf(format, args...)
-	tt.FailNow()
+	tt.record(EventFatal, fmt.Sprintf(format, args...), true)
+	tt.stop()
 }
 
 // Log mirrors the same-named function in testing.T: it records a log event a
-// la Println.
+// la Println.  The message is formatted exactly as testing.T formats it.
 func (tt *TestTester) Log(args ...interface{}) {
-	// STUPID HACK WARNING: this may not work.
-	if len(args) > 0 {
-		f := make([]string, len(args))
-		for i := range args {
-			f[i] = "%v"
-		}
-		format := strings.Join(f, " ")
-		tt.Logf(format, args...)
-	} else {
-		tt.Logf("")
-	}
+	tt.record(EventLog, sprintArgs(args), true)
 }
 
 // Logf mirrors the same-named function in testing.T: it records a log event
 // a la Printf.
 func (tt *TestTester) Logf(format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
-	tt.Logs = append(tt.Logs, msg)
+	tt.record(EventLog, fmt.Sprintf(format, args...), true)
 }
 
 // Skip mirrors the same-named function in testing.T: it is equivalent to Log
 // followed by SkipNow.
 func (tt *TestTester) Skip(args ...interface{}) {
-	tt.Log(args...)
-	tt.SkipNow()
+	tt.record(EventSkip, sprintArgs(args), true)
+	tt.stop()
 }
 
 // SkipNow mirrors the same-named function in testing.T: it marks the test as
 // having been skipped and sets the TestTester's Stopped property to true.
+// Under RunHelper it also stops execution by calling runtime.Goexit.
 func (tt *TestTester) SkipNow() {
-	tt.skipped = true
-	tt.Stopped = true // TODO: stop execution for real (exit goroutine)
-
+	tt.record(EventSkip, "", false)
+	tt.stop()
 }
 
 // Skipf mirrors the same-named function in testing.T: it is equivalent to
 // Logf followed by SkipNow.
 func (tt *TestTester) Skipf(format string, args ...interface{}) {
-	tt.Logf(format, args...)
-	tt.SkipNow()
+	tt.record(EventSkip, fmt.Sprintf(format, args...), true)
+	tt.stop()
 }
 
 // Skipped mirrors the same-named function in testing.T: it reports whether
 // the test was skipped.
 func (tt *TestTester) Skipped() bool {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
 	return tt.skipped
 }
+
+// Misuses returns descriptions of any misuse found when DetectMisuse is set.
+func (tt *TestTester) Misuses() []string {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+	misuses := make([]string, len(tt.misuses))
+	copy(misuses, tt.misuses)
+	return misuses
+}
+
+// stop sets the Stopped property and, under RunHelper, stops execution.
+// Only a goroutine running a function for tt is stopped; one left running
+// after a timeout is stopped without changing anything else.
+func (tt *TestTester) stop() {
+
+	gid := goroutineID()
+
+	tt.mu.Lock()
+	if tt.abandoned[gid] {
+		tt.mu.Unlock()
+		runtime.Goexit()
+	}
+	tt.Stopped = true
+	inRun := tt.runs[gid]
+	if !inRun {
+		tt.stops++ // else counted when the goroutine exits
+	}
+	if tt.DetectMisuse && !inRun {
+		for id := range tt.runs {
+			tt.misuse(fmt.Sprintf(
+				"FailNow or SkipNow called from goroutine %d, not test goroutine %d",
+				gid, id))
+			break
+		}
+	}
+	tt.mu.Unlock()
+
+	if inRun {
+		runtime.Goexit()
+	}
+}
+
+// misuse records a misuse and fails the test.  The caller must hold the lock.
+func (tt *TestTester) misuse(msg string) {
+	tt.misuses = append(tt.misuses, msg)
+	tt.failed = true
+}
+
+// sprintArgs formats args for Log and friends as testing.T does, that is
+// with fmt.Sprintln, which always puts spaces between operands.  The final
+// newline is trimmed by record.
+func sprintArgs(args []interface{}) string {
+	return fmt.Sprintln(args...)
+}
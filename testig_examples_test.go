@@ -50,7 +50,7 @@ func ExampleAssertPanicsWith() {
 
 	// Also catches non-panics as you would expect.
 	dontPanic := func() { return }
-	tt = testig.NewTestTester()
+	tt.Reset()
 	fmt.Println("Failed:", tt.Failed())
 
 	testig.AssertPanicsWith(tt, dontPanic, "uh oh", "and another")
@@ -62,3 +62,47 @@ func ExampleAssertPanicsWith() {
 	// Failed: false
 	// Failed: true
 }
+
+func ExampleTestTester_RunHelper() {
+
+	// A helper that does not stop on its own after a Fatal.
+	SloppyHelper := func(t testig.TT) {
+		t.Fatal("this should be the end")
+		t.Log("but it is not")
+	}
+
+	tt := testig.NewTestTester()
+	status := tt.RunHelper(SloppyHelper)
+
+	fmt.Println("Status:", status)
+	fmt.Println("Failed:", tt.Failed())
+	fmt.Println(tt.Logs)
+
+	// Output:
+	// Status: stopped
+	// Failed: true
+	// [this should be the end]
+}
+
+func ExampleTestTester_Snapshot() {
+
+	// A helper that complains about odd numbers.
+	EvenHelper := func(t testig.TT, n int) {
+		if n%2 != 0 {
+			t.Errorf("odd: %d", n)
+		}
+	}
+
+	tt := testig.NewTestTester()
+	for _, n := range []int{1, 2, 3} {
+		snap := tt.Snapshot()
+		EvenHelper(tt, n)
+		changes := tt.Diff(snap)
+		fmt.Println(n, changes.Failed, changes.Logs)
+	}
+
+	// Output:
+	// 1 true [odd: 1]
+	// 2 false []
+	// 3 true [odd: 3]
+}
@@ -22,6 +22,14 @@ func Test_NewTestTester(t *testing.T) {
 	assert.False(tt.Stopped, "Stopped is false at start")
 	assert.False(tt.Skipped(), "Skipped returns false")
 	assert.False(tt.Failed(), "Failed returns false")
+	assert.Equal(testig.DefaultTestTesterName, tt.Name(), "default Name")
+
+}
+
+func Test_NewNamedTestTester(t *testing.T) {
+
+	tt := testig.NewNamedTestTester("TestFoo")
+	assert.Equal(t, "TestFoo", tt.Name(), "Name as given")
 
 }
 
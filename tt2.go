@@ -0,0 +1,209 @@
+// tt2.go -- the extended TT2 interface.
+
+package testig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// TT2 extends TT with the methods testing.T has gained since the Go 1.0
+// days, and is likewise implemented by both the TestTester and testing.T
+// (and testing.B).
+//
+// Helper functions that need any of these methods should accept a TT2
+// argument:
+//
+//	func TempFileHelper(t TT2) string {
+//	    t.Helper()
+//	    return filepath.Join(t.TempDir(), "file.txt")
+//	}
+//
+// Note that the Go version in use must of course be recent enough for
+// testing.T to implement all of them.
+type TT2 interface {
+	TT
+	Chdir(dir string)
+	Cleanup(f func())
+	Context() context.Context
+	Helper()
+	Name() string
+	Setenv(key, value string)
+	TempDir() string
+}
+
+// Chdir mirrors the same-named function in testing.T: it changes the
+// current working directory to dir, and registers a cleanup function that
+// changes it back.  Because the working directory is process-wide, it panics
+// as testing.T does if tt or any of its parents has called Parallel.
+func (tt *TestTester) Chdir(dir string) {
+
+	tt.checkParallel()
+	oldwd, err := os.Getwd()
+	if err != nil {
+		tt.Fatal(err)
+		return
+	}
+	if err := os.Chdir(dir); err != nil {
+		tt.Fatal(err)
+		return
+	}
+	tt.Cleanup(func() {
+		if err := os.Chdir(oldwd); err != nil {
+			panic("testig: could not restore working directory: " +
+				err.Error())
+		}
+	})
+
+	// As in testing, keep PWD in sync if it is set.
+	if _, ok := os.LookupEnv("PWD"); ok {
+		if abs, err := filepath.Abs(dir); err == nil {
+			tt.Setenv("PWD", abs)
+		}
+	}
+}
+
+// Cleanup mirrors the same-named function in testing.T: it registers a
+// function to be called when the test and all its subtests complete.
+// Cleanup functions are called in last added, first called order.
+func (tt *TestTester) Cleanup(f func()) {
+	tt.mu.Lock()
+	tt.cleanups = append(tt.cleanups, f)
+	tt.mu.Unlock()
+}
+
+// RunCleanups runs, in last added, first called order, all functions
+// registered with Cleanup that have not yet been run.  Each is run as with
+// RunHelper, so FailNow and SkipNow stop only the cleanup function that
+// called them and a panic is recovered.  Before the first one is run, the
+// Context is canceled.
+//
+// RunCleanups is called automatically by RunHelper and Run, and need only be
+// called explicitly when a TestTester is used directly.
+func (tt *TestTester) RunCleanups() {
+
+	tt.mu.RLock()
+	cancel := tt.cancel
+	tt.mu.RUnlock()
+	if cancel != nil {
+		cancel()
+	}
+	for {
+		tt.mu.Lock()
+		if len(tt.cleanups) == 0 {
+			tt.mu.Unlock()
+			return
+		}
+		last := len(tt.cleanups) - 1
+		f := tt.cleanups[last]
+		tt.cleanups = tt.cleanups[:last]
+		tt.mu.Unlock()
+		tt.call(f)
+	}
+}
+
+// Context mirrors the same-named function in testing.T: it returns a
+// context that is canceled just before the Cleanup functions are run.
+func (tt *TestTester) Context() context.Context {
+
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if tt.ctx == nil {
+		tt.ctx, tt.cancel = context.WithCancel(context.Background())
+	}
+	return tt.ctx
+}
+
+// Helper mirrors the same-named function in testing.T: it marks the calling
+// function as a test helper function, to be skipped when determining the
+// source location of log events.
+func (tt *TestTester) Helper() {
+
+	var pc [1]uintptr
+	if runtime.Callers(2, pc[:]) == 0 {
+		return
+	}
+	frame, _ := runtime.CallersFrames(pc[:]).Next()
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if tt.helpers == nil {
+		tt.helpers = map[string]bool{}
+	}
+	tt.helpers[frame.Function] = true
+}
+
+// Setenv mirrors the same-named function in testing.T: it sets the
+// environment variable key to value, and registers a cleanup function that
+// restores its previous state.  Because the environment is process-wide, it
+// panics as testing.T does if tt or any of its parents has called Parallel.
+func (tt *TestTester) Setenv(key, value string) {
+
+	tt.checkParallel()
+	prev, existed := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		tt.Fatalf("cannot set environment variable: %v", err)
+		return
+	}
+	if existed {
+		tt.Cleanup(func() { os.Setenv(key, prev) })
+	} else {
+		tt.Cleanup(func() { os.Unsetenv(key) })
+	}
+}
+
+// TempDir mirrors the same-named function in testing.T: it returns a new,
+// unique temporary directory for the test to use, which is removed when the
+// Cleanup functions are run.
+func (tt *TestTester) TempDir() string {
+
+	tt.mu.Lock()
+	parent := tt.tempDir
+	seq := tt.tempSeq
+	tt.tempSeq++
+	tt.mu.Unlock()
+
+	if parent == "" {
+		// Keep the pattern short and free of path separators, as in testing.
+		pattern := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`<>:"/\|?*`, r) {
+				return '_'
+			}
+			return r
+		}, tt.name)
+		if len(pattern) > 64 {
+			pattern = pattern[:64]
+		}
+		dir, err := os.MkdirTemp("", pattern)
+		if err != nil {
+			tt.Fatalf("TempDir: %v", err)
+			return ""
+		}
+		tt.mu.Lock()
+		if tt.tempDir == "" {
+			tt.tempDir = dir
+			tt.cleanups = append(tt.cleanups, func() {
+				os.RemoveAll(dir)
+				tt.mu.Lock()
+				tt.tempDir = ""
+				tt.tempSeq = 0
+				tt.mu.Unlock()
+			})
+		} else {
+			// Lost a race with another goroutine: use its directory.
+			os.Remove(dir)
+		}
+		parent = tt.tempDir
+		tt.mu.Unlock()
+	}
+
+	dir := filepath.Join(parent, fmt.Sprintf("%03d", seq))
+	if err := os.Mkdir(dir, 0777); err != nil {
+		tt.Fatalf("TempDir: %v", err)
+		return ""
+	}
+	return dir
+}
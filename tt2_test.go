@@ -0,0 +1,188 @@
+// tt2_test.go
+
+package testig_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/biztos/testig"
+)
+
+// Everybody implements TT2.
+var _ testig.TT2 = (*testing.T)(nil)
+var _ testig.TT2 = (*testing.B)(nil)
+var _ testig.TT2 = (*testig.TestTester)(nil)
+
+func Test_TestTester_Cleanup(t *testing.T) {
+
+	assert := assert.New(t)
+
+	got := []string{}
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {
+		t2 := t.(testig.TT2)
+		t2.Cleanup(func() { got = append(got, "first") })
+		t2.Cleanup(func() {
+			got = append(got, "second")
+			t.FailNow()
+			got = append(got, "not reached")
+		})
+		t2.Cleanup(func() { got = append(got, "third") })
+		got = append(got, "body")
+	})
+	assert.Equal([]string{"body", "third", "second", "first"}, got,
+		"cleanups run LIFO after the function, FailNow stops only one")
+	assert.True(tt.Failed(), "failure in cleanup recorded")
+
+	// Already run, so not run again.
+	tt.RunCleanups()
+	assert.Equal(4, len(got), "cleanups only run once")
+
+}
+
+func Test_TestTester_Cleanup_Subtests(t *testing.T) {
+
+	assert := assert.New(t)
+
+	got := []string{}
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {
+		t.(testig.TT2).Cleanup(func() { got = append(got, "parent") })
+		testig.RunSubtest(t, "sub", func(t testig.TT) {
+			t.(testig.TT2).Cleanup(func() { got = append(got, "sub") })
+		})
+		got = append(got, "parent body")
+	})
+	assert.Equal([]string{"sub", "parent body", "parent"}, got,
+		"subtest cleanups run when the subtest completes")
+
+}
+
+func Test_TestTester_Cleanup_Panic(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	tt.Cleanup(func() { panic("in cleanup") })
+	tt.RunCleanups()
+	assert.Equal("in cleanup", tt.Panic, "panic recovered")
+	assert.True(tt.Failed(), "Failed returns true")
+
+}
+
+func Test_TestTester_Context(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tt := testig.NewTestTester()
+	ctx := tt.Context()
+	assert.Equal(ctx, tt.Context(), "same context returned")
+
+	var errInCleanup error
+	tt.Cleanup(func() { errInCleanup = ctx.Err() })
+	assert.NoError(ctx.Err(), "context not canceled before cleanup")
+	tt.RunCleanups()
+	assert.Error(errInCleanup, "context canceled before cleanup")
+
+}
+
+func Test_TestTester_Setenv(t *testing.T) {
+
+	assert := assert.New(t)
+
+	const existing = "TESTIG_TEST_SETENV_EXISTING"
+	const missing = "TESTIG_TEST_SETENV_MISSING"
+	os.Setenv(existing, "before")
+	defer os.Unsetenv(existing)
+	os.Unsetenv(missing)
+
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {
+		t.(testig.TT2).Setenv(existing, "during")
+		t.(testig.TT2).Setenv(missing, "during")
+		assert.Equal("during", os.Getenv(existing), "existing set")
+		assert.Equal("during", os.Getenv(missing), "missing set")
+	})
+	assert.Equal("before", os.Getenv(existing), "existing restored")
+	_, found := os.LookupEnv(missing)
+	assert.False(found, "missing unset again")
+
+}
+
+func Test_TestTester_Setenv_Failure(t *testing.T) {
+
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {
+		t.(testig.TT2).Setenv("", "bad")
+	})
+	assert.True(t, tt.Failed(), "failed to set bad key")
+	assert.Regexp(t, "^cannot set environment variable", tt.Logs[0])
+
+}
+
+func Test_TestTester_Chdir(t *testing.T) {
+
+	assert := assert.New(t)
+
+	orig, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	tt := testig.NewTestTester()
+	tt.RunHelper(func(tx testig.TT) {
+		tx.(testig.TT2).Chdir(dir)
+		wd, _ := os.Getwd()
+		want, _ := filepath.EvalSymlinks(dir)
+		got, _ := filepath.EvalSymlinks(wd)
+		assert.Equal(want, got, "changed directory")
+	})
+	wd, _ := os.Getwd()
+	assert.Equal(orig, wd, "directory restored")
+	assert.False(tt.Failed(), "Failed returns false")
+
+	tt = testig.NewTestTester()
+	tt.RunHelper(func(t testig.TT) {
+		t.(testig.TT2).Chdir(filepath.Join(dir, "nonesuch"))
+	})
+	assert.True(tt.Failed(), "Failed on bad directory")
+	assert.True(tt.Stopped, "Stopped on bad directory")
+
+}
+
+func Test_TestTester_TempDir(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var first, second string
+	tt := testig.NewNamedTestTester("TestFoo/with/slashes")
+	tt.RunHelper(func(t testig.TT) {
+		first = t.(testig.TT2).TempDir()
+		second = t.(testig.TT2).TempDir()
+		assert.DirExists(first, "first exists")
+		assert.DirExists(second, "second exists")
+	})
+	assert.NotEqual(first, second, "directories are unique")
+	assert.NoDirExists(first, "first removed")
+	assert.NoDirExists(second, "second removed")
+	assert.NoDirExists(filepath.Dir(first), "parent removed")
+
+}
+
+func Test_TestTester_Helper(t *testing.T) {
+
+	// Helper frames do not show up in Logs; just make sure it is harmless.
+	tt := testig.NewTestTester()
+	helper := func(t testig.TT2) {
+		t.Helper()
+		t.Log("from helper")
+	}
+	helper(tt)
+	assert.Equal(t, []string{"from helper"}, tt.Logs, "logged")
+
+}